@@ -1,8 +1,27 @@
 package cmd
 
-import "strings"
+import (
+	"fmt"
+	"strings"
 
-func buildBlacklists(blacklist []string) ([]string, []string) {
+	"github.com/caarlos0/org-stats/orgstats"
+)
+
+// buildAllowlists is like buildBlacklists, for --only, but every entry must
+// say whether it is a user or a repo: a bare entry would restrict the stats
+// to repos named like the user as well, which leaves nothing.
+func buildAllowlists(allowlist []string) ([]string, []string, error) {
+	for _, a := range allowlist {
+		if !strings.HasPrefix(a, "user:") && !strings.HasPrefix(a, "repo:") {
+			return nil, nil, fmt.Errorf("'%s' should be either 'user:%s' or 'repo:%s'", a, a, a)
+		}
+	}
+	return buildBlacklists(allowlist)
+}
+
+// buildBlacklists splits the given entries into users and repos, validating
+// their patterns.
+func buildBlacklists(blacklist []string) ([]string, []string, error) {
 	var userBlacklist []string
 	var repoBlacklist []string
 	for _, b := range blacklist {
@@ -15,5 +34,10 @@ func buildBlacklists(blacklist []string) ([]string, []string) {
 			repoBlacklist = append(repoBlacklist, b)
 		}
 	}
-	return userBlacklist, repoBlacklist
+	for _, entries := range [][]string{userBlacklist, repoBlacklist} {
+		if _, err := orgstats.ParsePatterns(entries); err != nil {
+			return nil, nil, err
+		}
+	}
+	return userBlacklist, repoBlacklist, nil
 }
@@ -7,14 +7,38 @@ import (
 )
 
 func TestBuildBlacklists(t *testing.T) {
-	users, repos := buildBlacklists([]string{
+	users, repos, err := buildBlacklists([]string{
 		"user:foo",
 		"repo:bar",
 		"something else",
 		"yada:yada",
+		"user:*-bot",
+		"repo:re:^sandbox-",
 	})
 
 	is := is.New(t)
-	is.Equal(users, []string{"foo", "something else", "yada:yada"})
-	is.Equal(repos, []string{"bar", "something else", "yada:yada"})
+	is.NoErr(err)
+	is.Equal(users, []string{"foo", "something else", "yada:yada", "*-bot"})
+	is.Equal(repos, []string{"bar", "something else", "yada:yada", "re:^sandbox-"})
+}
+
+func TestBuildBlacklistsInvalid(t *testing.T) {
+	for _, entry := range []string{"user:re:(", "repo:foo[", "re:*"} {
+		t.Run(entry, func(t *testing.T) {
+			_, _, err := buildBlacklists([]string{entry})
+			is.New(t).True(err != nil)
+		})
+	}
+}
+
+func TestBuildAllowlists(t *testing.T) {
+	users, repos, err := buildAllowlists([]string{"user:alice", "repo:re:^svc-"})
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(users, []string{"alice"})
+	is.Equal(repos, []string{"re:^svc-"})
+
+	_, _, err = buildAllowlists([]string{"user:alice", "bob"})
+	is.True(err != nil) // bare entry
 }
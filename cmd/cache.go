@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/caarlos0/org-stats/httpcache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages org-stats's http cache",
+	Args:  cobra.NoArgs,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:          "clear",
+	Short:        "Removes all cached responses",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		dir, err := httpCacheDir()
+		if err != nil {
+			return err
+		}
+		if err := httpcache.Clear(dir); err != nil {
+			return err
+		}
+		fmt.Println("cleared", dir)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func resolveCacheDir() (string, error) {
+	if cacheDir != "" {
+		return cacheDir, nil
+	}
+	return httpcache.DefaultDir()
+}
+
+// httpCacheDir returns where responses are cached, apart from the
+// checkpoints and git clones also kept in the cache dir.
+func httpCacheDir() (string, error) {
+	dir, err := resolveCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "http"), nil
+}
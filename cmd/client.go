@@ -1,22 +1,24 @@
 package cmd
 
 import (
-	"context"
+	"net/http"
 
 	"github.com/google/go-github/v39/github"
 	"golang.org/x/oauth2"
 )
 
-func newClient(ctx context.Context, token, baseURL string) (*github.Client, error) {
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+func newClient(token, baseURL string, base http.RoundTripper) (*github.Client, error) {
+	httpClient := &http.Client{Transport: base}
+	if token != "" {
+		httpClient.Transport = &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   base,
+		}
+	}
 
 	if baseURL == "" {
-		if token == "" {
-			return github.NewClient(nil), nil
-		} else {
-			return github.NewClient(oauth2.NewClient(ctx, ts)), nil
-		}
+		return github.NewClient(httpClient), nil
 	}
 
-	return github.NewEnterpriseClient(baseURL, "", oauth2.NewClient(ctx, ts))
+	return github.NewEnterpriseClient(baseURL, "", httpClient)
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caarlos0/duration"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseTime parses either an absolute date, as in 2026-07-01, or a duration
+// to look back from now, as in 3mo.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	d, err := duration.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither a date nor a duration", s)
+	}
+	return time.Now().UTC().Add(-1 * time.Duration(d)), nil
+}
+
+// parseUntil is like parseTime, but absolute dates include the whole day.
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return parseTime(s)
+}
+
+// periodFlags describes the period as given by the user, so a checkpoint of
+// a relative period, which covers other times on each run, can be resumed.
+func periodFlags(since, from, until string) string {
+	return fmt.Sprintf("since=%s from=%s until=%s", since, from, until)
+}
+
+// parsePeriod parses the --since, --from and --until flags into the period
+// to gather stats for, zero times meaning unbounded.
+func parsePeriod(since, from, until string) (time.Time, time.Time, error) {
+	sinceD, err := duration.Parse(since)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid --since duration: '%s'", since)
+	}
+	if from != "" && sinceD > 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("--since and --from can't be used together")
+	}
+
+	var sinceT, untilT time.Time
+	if sinceD > 0 {
+		sinceT = time.Now().UTC().Add(-1 * time.Duration(sinceD))
+	}
+	if from != "" {
+		sinceT, err = parseTime(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	if until != "" {
+		untilT, err = parseUntil(until)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if !sinceT.IsZero() && !untilT.IsZero() && !sinceT.Before(untilT) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--until must be after --from")
+	}
+	return sinceT, untilT, nil
+}
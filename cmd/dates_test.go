@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestParseTime(t *testing.T) {
+	is := is.New(t)
+
+	from, err := parseTime("2026-07-01")
+	is.NoErr(err)
+	is.Equal(from, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	until, err := parseUntil("2026-09-30")
+	is.NoErr(err)
+	is.Equal(until, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	ago, err := parseTime("1w")
+	is.NoErr(err)
+	is.True(time.Since(ago)-7*24*time.Hour < time.Minute)
+
+	_, err = parseTime("yesterday")
+	is.True(err != nil)
+}
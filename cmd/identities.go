@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/org-stats/orgstats"
+)
+
+// loadIdentities reads the --identities file, if any.
+func loadIdentities(path string) (*orgstats.Identities, error) {
+	if path == "" {
+		return nil, nil
+	}
+	ids, err := orgstats.LoadIdentities(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --identities: %w", err)
+	}
+	return ids, nil
+}
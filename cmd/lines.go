@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// readLines reads the non-empty lines of the given file, ignoring comments
+// starting with '#'.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
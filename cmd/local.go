@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/caarlos0/org-stats/cmd/ui"
+	"github.com/caarlos0/org-stats/orgstats"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local <dir>...",
+	Short: "Get the contributor stats summary from git repositories already on disk",
+	Long: `Get the contributor stats summary from git repositories already on disk, without any API access.
+
+Each directory is either a git repository, owned by its parent directory, or a directory containing git repositories, owned by it.
+The owner is what ` + "`--by org`" + ` breaks the results down by.
+
+Only lines added, removed and number of commits are available, from the default branch of each repository.
+Authors are identified by their GitHub login if they committed with a GitHub noreply email, and by their email otherwise.
+Use ` + "`--identities`" + ` to merge the emails of each person, for example into their GitHub login.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, dirs []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		if by != ui.ByUser && by != ui.ByOrg && by != ui.ByRepo {
+			return fmt.Errorf("invalid --by: '%s'", by)
+		}
+		sinceT, untilT, err := parsePeriod(since, from, until)
+		if err != nil {
+			return err
+		}
+		userBlacklist, repoBlacklist, err := buildBlacklists(blacklist)
+		if err != nil {
+			return fmt.Errorf("invalid --blacklist: %w", err)
+		}
+		userAllowlist, repoAllowlist, err := buildAllowlists(only)
+		if err != nil {
+			return fmt.Errorf("invalid --only: %w", err)
+		}
+		if _, err := orgstats.ParsePatterns(botPatterns); err != nil {
+			return fmt.Errorf("invalid --bot: %w", err)
+		}
+		identities, err := loadIdentities(identitiesPath)
+		if err != nil {
+			return err
+		}
+		include, err := orgstats.ParsePatterns(includeRepos)
+		if err != nil {
+			return fmt.Errorf("invalid --include-repo: %w", err)
+		}
+		exclude, err := orgstats.ParsePatterns(excludeRepos)
+		if err != nil {
+			return fmt.Errorf("invalid --exclude-repo: %w", err)
+		}
+
+		csv, closeCSV, err := createCSV(csvPath)
+		if err != nil {
+			return err
+		}
+		defer closeCSV()
+
+		f, err := tea.LogToFile(filepath.Join(os.TempDir(), "org-stats.log"), "org-stats")
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		collector := orgstats.NewCollector(
+			nil,
+			"",
+			orgstats.WithLocal(dirs...),
+			orgstats.WithUserBlacklist(userBlacklist...),
+			orgstats.WithRepoBlacklist(repoBlacklist...),
+			orgstats.WithUserAllowlist(userAllowlist...),
+			orgstats.WithRepoAllowlist(repoAllowlist...),
+			orgstats.WithSince(sinceT),
+			orgstats.WithUntil(untilT),
+			orgstats.WithExcludeBots(excludeBots),
+			orgstats.WithBotPatterns(botPatterns...),
+			orgstats.WithIdentities(identities),
+			orgstats.WithRepoFilter(orgstats.RepoFilter{Include: include, Exclude: exclude}),
+			orgstats.WithConcurrency(concurrency),
+		)
+
+		if dryRun {
+			names, err := collector.Repositories(ctx)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		p := tea.NewProgram(
+			ui.NewInitialModel(ctx, cancel, collector, top, by, csv),
+			tea.WithContext(ctx),
+		)
+		_, err = p.Run()
+		if errors.Is(err, context.Canceled) || errors.Is(err, tea.ErrInterrupted) {
+			// the user asked to quit, which is not an error.
+			return nil
+		}
+		return err
+	},
+}
+
+func init() {
+	localCmd.Flags().StringSliceVarP(&blacklist, "blacklist", "b", []string{}, "blacklist repos and/or users")
+	localCmd.Flags().StringSliceVar(&only, "only", []string{}, "only consider these users and/or repos, as 'user:<pattern>' or 'repo:<pattern>'")
+	localCmd.Flags().IntVar(&top, "top", 3, "how many users to show")
+	localCmd.Flags().StringVar(&by, "by", ui.ByUser, "how to break down the results: 'user' merges all owners, 'org' shows each one on its own, 'repo' shows the contributors of each repository")
+	localCmd.Flags().StringVar(&since, "since", "0s", "time to look back to gather info (0s means everything)")
+	localCmd.Flags().StringVar(&from, "from", "", "gather info from this date, e.g. 2026-07-01, or duration ago")
+	localCmd.Flags().StringVar(&until, "until", "", "gather info until this date, inclusive, e.g. 2026-09-30, or duration ago")
+	localCmd.Flags().BoolVar(&excludeBots, "exclude-bots", false, "exclude bots from the stats entirely")
+	localCmd.Flags().StringSliceVar(&botPatterns, "bot", []string{}, "more users to consider bots, as globs or 're:' regular expressions")
+	localCmd.Flags().StringVar(&identitiesPath, "identities", "", "file merging the logins and emails of each person, in the .mailmap format plus '@login alias...' lines")
+	localCmd.Flags().StringSliceVar(&includeRepos, "include-repo", []string{}, "only scan repositories matching these globs or 're:' regular expressions")
+	localCmd.Flags().StringSliceVar(&excludeRepos, "exclude-repo", []string{}, "ignore repositories matching these globs or 're:' regular expressions")
+	localCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the repositories that would be scanned")
+	localCmd.Flags().StringVar(&csvPath, "csv-path", "", "path to write a csv file with all data collected")
+	localCmd.Flags().IntVar(&concurrency, "concurrency", 1, "how many repositories to scan in parallel")
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePercentage parses either '20%' or '0.2' into 0.2.
+func parsePercentage(s string) (float64, error) {
+	value := strings.TrimSpace(s)
+	div := 1.0
+	if strings.HasSuffix(value, "%") {
+		value = strings.TrimSuffix(value, "%")
+		div = 100
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a percentage", s)
+	}
+	f /= div
+	if f < 0 || f >= 1 {
+		return 0, fmt.Errorf("'%s' must be between 0%% and 100%%", s)
+	}
+	return f, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParsePercentage(t *testing.T) {
+	for in, out := range map[string]float64{
+		"20%":  0.2,
+		"0.2":  0.2,
+		"0%":   0,
+		"2.5%": 0.025,
+	} {
+		t.Run(in, func(t *testing.T) {
+			is := is.New(t)
+			f, err := parsePercentage(in)
+			is.NoErr(err)
+			is.Equal(f, out)
+		})
+	}
+
+	for _, in := range []string{"100%", "-1%", "abc", "1.5"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := parsePercentage(in)
+			is.New(t).True(err != nil)
+		})
+	}
+}
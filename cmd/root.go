@@ -2,28 +2,67 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
+	"strings"
+	"syscall"
 
-	"github.com/caarlos0/duration"
 	"github.com/caarlos0/org-stats/cmd/ui"
+	"github.com/caarlos0/org-stats/github_errors"
+	"github.com/caarlos0/org-stats/httpcache"
+	"github.com/caarlos0/org-stats/orgstats"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
 var (
 	token          string
-	organization   string
+	organizations  []string
+	users          []string
+	repos          []string
+	reposFile      string
 	githubURL      string
 	since          string
+	from           string
+	until          string
 	csvPath        string
 	blacklist      []string
+	only           []string
 	top            int
 	includeReviews bool
+	reviewMembers  bool
+	reviewTeams    []string
+	includePRs     bool
+	reviewDetails  bool
+	includeIssues  bool
 	excludeForks   bool
+	api            string
+	concurrency    int
+	reserve        string
+	cacheDir       string
+	useCache       bool
+	useGit         bool
+	gitDir         string
+	checkpoint     string
+	resume         bool
+	by             string
+	includeRepos   []string
+	excludeRepos   []string
+	topics         []string
+	excludeTopics  []string
+	languages      []string
+	visibilities   []string
+	archived       string
+	pushedSince    string
+	dryRun         bool
+	excludeBots    bool
+	botPatterns    []string
+	identitiesPath string
+	teams          []string
 )
 
 func Execute() {
@@ -37,20 +76,54 @@ func init() {
 	rootCmd.Flags().StringVar(&token, "token", "", "github api token (default $GITHUB_TOKEN)")
 	_ = rootCmd.MarkFlagRequired(token)
 
-	rootCmd.Flags().StringVarP(&organization, "org", "o", "", "github organization to scan")
-	_ = rootCmd.MarkFlagRequired("org")
+	rootCmd.Flags().StringSliceVarP(&organizations, "org", "o", []string{}, "github organizations to scan")
+	rootCmd.Flags().StringSliceVar(&users, "user", []string{}, "github user accounts whose repositories to scan")
+	rootCmd.Flags().StringSliceVar(&repos, "repo", []string{}, "repositories to scan, in the owner/name form")
+	rootCmd.Flags().StringVar(&reposFile, "repo-file", "", "file with repositories to scan, one owner/name per line")
 
 	rootCmd.Flags().StringSliceVarP(&blacklist, "blacklist", "b", []string{}, "blacklist repos and/or users")
+	rootCmd.Flags().StringSliceVar(&only, "only", []string{}, "only consider these users and/or repos, as 'user:<pattern>' or 'repo:<pattern>'")
 	rootCmd.Flags().IntVar(&top, "top", 3, "how many users to show")
+	rootCmd.Flags().StringVar(&by, "by", ui.ByUser, "how to break down the results: 'user' merges all organizations, 'org' shows each one on its own, 'repo' shows the contributors of each repository, 'team' rolls the stats up per organization team")
 	rootCmd.Flags().StringVar(&githubURL, "github-url", "", "custom github base url (if using github enterprise)")
 	rootCmd.Flags().StringVar(&since, "since", "0s", "time to look back to gather info (0s means everything)")
+	rootCmd.Flags().StringVar(&from, "from", "", "gather info from this date, e.g. 2026-07-01, or duration ago")
+	rootCmd.Flags().StringVar(&until, "until", "", "gather info until this date, inclusive, e.g. 2026-09-30, or duration ago")
 	rootCmd.Flags().BoolVar(&includeReviews, "include-reviews", false, "include pull request reviews in the stats")
+	rootCmd.Flags().BoolVar(&reviewMembers, "review-members", false, "include the pull request reviews of all organization members, even the ones that did not commit; implies --include-reviews")
+	rootCmd.Flags().StringSliceVar(&reviewTeams, "review-team", []string{}, "include the pull request reviews of the members of the given team, as 'org/slug' or a slug looked up on every organization, even the ones that did not commit; implies --include-reviews (can be repeated)")
+	rootCmd.Flags().StringSliceVar(&teams, "team", []string{}, "only consider the members of the given team, as 'org/slug' or a slug looked up on every organization (can be repeated)")
+	rootCmd.Flags().BoolVar(&includePRs, "include-prs", false, "include pull requests opened, merged and closed, median time to merge and median size in the stats")
+	rootCmd.Flags().BoolVar(&reviewDetails, "include-review-details", false, "include approvals, change requests, review comments and median time to first review in the stats")
+	rootCmd.Flags().BoolVar(&includeIssues, "include-issues", false, "include issues opened, closed and commented on in the stats")
+	rootCmd.Flags().BoolVar(&excludeBots, "exclude-bots", false, "exclude bots from the stats entirely")
+	rootCmd.Flags().StringSliceVar(&botPatterns, "bot", []string{}, "more users to consider bots, as globs or 're:' regular expressions")
+	rootCmd.Flags().StringVar(&identitiesPath, "identities", "", "file merging the logins and emails of each person, in the .mailmap format plus '@login alias...' lines")
 	rootCmd.Flags().BoolVar(&excludeForks, "exclude-forks", false, "exclude forked repositories from the stats")
+	rootCmd.Flags().StringSliceVar(&includeRepos, "include-repo", []string{}, "only scan repositories matching these globs or 're:' regular expressions")
+	rootCmd.Flags().StringSliceVar(&excludeRepos, "exclude-repo", []string{}, "ignore repositories matching these globs or 're:' regular expressions")
+	rootCmd.Flags().StringSliceVar(&topics, "topic", []string{}, "only scan repositories with any of these topics")
+	rootCmd.Flags().StringSliceVar(&excludeTopics, "exclude-topic", []string{}, "ignore repositories with any of these topics")
+	rootCmd.Flags().StringSliceVar(&languages, "language", []string{}, "only scan repositories with any of these primary languages")
+	rootCmd.Flags().StringSliceVar(&visibilities, "visibility", []string{}, "only scan repositories with any of these visibilities: public, private or internal")
+	rootCmd.Flags().StringVar(&archived, "archived", "include", "what to do with archived repositories: include, exclude or only")
+	rootCmd.Flags().StringVar(&pushedSince, "pushed-since", "", "only scan repositories pushed to since this date or duration")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the repositories that would be scanned")
 	rootCmd.Flags().StringVar(&csvPath, "csv-path", "", "path to write a csv file with all data collected")
+	rootCmd.Flags().StringVar(&api, "api", orgstats.APIREST, "github api to use: 'rest', or 'graphql' to list repositories and pull requests, and search reviews, with fewer requests")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", 1, "how many repositories to scan in parallel")
+	rootCmd.Flags().StringVar(&reserve, "rate-limit-reserve", "0%", "percentage of the api rate limit to leave for other tools")
+
+	rootCmd.Flags().BoolVar(&useGit, "git", false, "compute line stats from bare clones of the repositories, exact to the day, instead of asking github")
+	rootCmd.Flags().StringVar(&gitDir, "git-dir", "", "where to bare clone the repositories with --git (default <cache-dir>/repos); implies --git")
+	rootCmd.Flags().BoolVar(&useCache, "cache", false, "cache responses on disk, and revalidate them on later runs")
+	rootCmd.Flags().StringVar(&checkpoint, "checkpoint", "", "file to save the progress to, so it can be resumed (default is inside the cache dir)")
+	rootCmd.Flags().BoolVar(&resume, "resume", false, "resume an interrupted run from the checkpoint file")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "where to store the http cache, checkpoints and git clones (default is inside the user cache dir)")
 
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 
-	rootCmd.AddCommand(versionCmd, docsCmd, manCmd)
+	rootCmd.AddCommand(versionCmd, docsCmd, manCmd, cacheCmd, localCmd)
 }
 
 var rootCmd = &cobra.Command{
@@ -58,18 +131,37 @@ var rootCmd = &cobra.Command{
 	Short: "Get the contributor stats summary from all repos of any given organization",
 	Long: `org-stats can be used to get an overall sense of your org's contributors.
 
-It uses the GitHub API to grab the repositories in the given organization.
-Then, iterating one by one, it gets statistics of lines added, removed and number of commits of contributors.
+It uses the GitHub API to grab the repositories in the given organizations.
+Then, using up to ` + "`--concurrency`" + ` workers, it gets statistics of lines added, removed and number of commits of contributors, either from the GitHub API or, with ` + "`--git`" + `, from bare clones of the repositories.
 After that, if opted in, it does several searches to get the number of pull requests reviewed by each of the previously find contributors.
 Finally, it prints a rank by each category.
 
 
 Important notes:
 * GitHub's API rate limits for unauthenticated requests have been lowered significantly in the recent past. Using the ` + "`--token`" + ` option for compiling stats will speed up gathering of data considerably, since for authenticated requests it will be less likely that rate-limiting timelocks have to be awaited.
-* The ` + "`--since`" + ` filter does not work "that well" because GitHub summarizes thedata by week, so the data is not as granular as it should be.
-* The ` + "`--include-reviews`" + ` only grabs reviews from users that had contributions on the previous step.
-* In the ` + "`--blacklist`" + ` option, 'foo' blacklists both the 'foo' user and 'foo' repo, while 'user:foo' blacklists only the user and 'repo:foo' only the repository.
+* The ` + "`--since`" + `, ` + "`--from`" + ` and ` + "`--until`" + ` filters do not work "that well" because GitHub summarizes thedata by week, so the data is not as granular as it should be. GitHub also only computes them for the last 10k commits of each repository. With ` + "`--git`" + `, both limits go away, at the cost of cloning every repository.
+* Repositories already on disk, from any git host, can be analyzed without any API access with ` + "`org-stats local <dir>...`" + `.
+* The ` + "`--from`" + ` and ` + "`--until`" + ` options accept absolute dates, like '--from 2026-07-01 --until 2026-09-30', both inclusive, or durations to look back. The period is included in all outputs, so reports are reproducible.
+* The ` + "`--include-reviews`" + ` only grabs reviews from users that had contributions on the previous step. Use ` + "`--review-members`" + ` or ` + "`--review-team`" + ` to also grab reviews from organization or team members that did not commit.
+* The ` + "`--include-prs`" + ` option lists the pull requests created within the period in each repository, and gets each one of them for its size, so it needs many more requests.
+* The ` + "`--include-review-details`" + ` option lists the reviews and review comments of each pull request created within the period, so, just like ` + "`--include-prs`" + `, it needs many more requests. Unlike ` + "`--include-reviews`" + `, it counts every reviewer, even the ones that never committed.
+* The ` + "`--include-issues`" + ` option lists the issues, issue events and issue comments of each repository, and ranks the ones that closed and commented on the most issues as triage champions.
+* The ` + "`--org`" + ` option can be repeated to scan several organizations at once. Use ` + "`--by org`" + ` to get the results of each one on its own instead of merged.
+* Instead of, or along with, organizations, the repositories of user accounts can be scanned with ` + "`--user`" + `, and specific repositories with ` + "`--repo owner/name`" + ` or ` + "`--repo-file`" + `. With ` + "`--by org`" + `, results are then broken down by repository owner.
+* Use ` + "`--by repo`" + ` to see who drives the changes of each repository. The ` + "`--include-reviews`" + ` counts are left out, but ` + "`--include-review-details`" + ` shows each repository's top reviewers too.
+* Use ` + "`--by team`" + ` to roll the stats up per team of the organizations, people on several teams counting towards each one of them. Use ` + "`--team`" + ` to only consider the members of the given teams, which are then the only ones shown with ` + "`--by team`" + `.
+* Repositories can be filtered by name with ` + "`--include-repo`" + ` and ` + "`--exclude-repo`" + `, which accept globs like 'svc-*' and regular expressions prefixed with 're:', matched against both 'name' and 'owner/name'. They can also be filtered by ` + "`--topic`" + `, ` + "`--exclude-topic`" + `, ` + "`--language`" + `, ` + "`--visibility`" + `, ` + "`--archived`" + ` and ` + "`--pushed-since`" + `. Use ` + "`--dry-run`" + ` to list the repositories that would be scanned.
+* In the ` + "`--blacklist`" + ` option, 'foo' blacklists both the 'foo' user and 'foo' repo, while 'user:foo' blacklists only the user and 'repo:foo' only the repository. 'repo:org/foo' blacklists only the 'foo' repository of the 'org' organization. Entries can also be globs, like 'user:*-bot' or 'repo:sandbox-*', or regular expressions prefixed with 're:', like 'user:re:^ci-[0-9]+$'.
+* Bots, that is GitHub Apps, users ending in '[bot]' and users matching ` + "`--bot`" + `, are left out of the champions and shown in a separate automation section instead. Use ` + "`--exclude-bots`" + ` to ignore them entirely.
+* People committing with several accounts or emails can be merged with ` + "`--identities`" + `, a file in the .mailmap format plus lines like '@alice @alice-work alice@example.com', merging the given logins and emails into the first login. Commits GitHub can not link to any account are shown as '` + orgstats.Unattributed + `' instead of being left out.
+* The ` + "`--only`" + ` option takes entries in the same format as ` + "`--blacklist`" + `, and restricts the stats to the matching users and/or repositories. Each entry must be prefixed with either 'user:' or 'repo:', as restricting both would usually leave nothing.
 * The ` + "`--since`" + ` option accepts all the regular time. Accepts any duration Go standard library accepts, plus a few more: 1y (365d), 1mo (30d), 1w (7d) and 1d (24h).
+* With ` + "`--api graphql`" + `, repositories are listed 100 at a time, pull requests 50 at a time along with their sizes and first 100 reviews, and reviews are searched for many users in a single request, which needs far fewer requests on large organizations. Line stats and issues are only gathered with the REST API.
+* The ` + "`--concurrency`" + ` option controls how many repositories are scanned in parallel. Higher values are faster, but make it more likely to hit GitHub's secondary rate limits, in which case all workers wait together.
+* Requests are spread evenly over each rate limit window, so the quota lasts until it resets. The ` + "`--rate-limit-reserve`" + ` option leaves part of it, e.g. '20%', untouched for other tools using the same token. The search API, used by ` + "`--include-reviews`" + `, has its own, much smaller, limit and is paced separately.
+* With ` + "`--cache`" + `, responses are cached on disk, see ` + "`--cache-dir`" + `, and revalidated on later runs, so unchanged data doesn't count against the rate limit. Use ` + "`org-stats cache clear`" + ` to remove them.
+* Progress is saved to a checkpoint file while gathering, see ` + "`--checkpoint`" + `. If a run is interrupted, run it again with the same options plus ` + "`--resume`" + ` to continue where it stopped. Resuming with options changing what is gathered, like ` + "`--blacklist`" + ` or ` + "`--include-prs`" + `, is refused, as it would mix incompatible stats.
+* GitHub computes contributor stats in background, so they are retried with exponential backoff for a few minutes. Repositories still not ready after that are listed as "stats unavailable" at the end.
 * The ` + "`--token`" + ` token permissions need to include 'repo - Full control of private repositories'. Required only if you need to fetch data from private repositories in your organization.
 }`,
 	PreRun: func(*cobra.Command, []string) {
@@ -78,55 +170,204 @@ Important notes:
 		}
 	},
 	RunE: func(*cobra.Command, []string) error {
-		ctx := context.Background()
-		client, err := newClient(ctx, token, githubURL)
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		reserveF, err := parsePercentage(reserve)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid --rate-limit-reserve: %w", err)
 		}
 
-		sinceD, err := duration.Parse(since)
+		rateLimits := githuberrors.NewTransport(http.DefaultTransport)
+		rateLimits.Budget = githuberrors.NewBudget(reserveF)
+		var transport http.RoundTripper = rateLimits
+		if useCache {
+			dir, err := httpCacheDir()
+			if err != nil {
+				return err
+			}
+			transport = httpcache.New(dir, rateLimits)
+		}
+		client, err := newClient(token, githubURL, transport)
 		if err != nil {
-			return fmt.Errorf("invalid --since duration: '%s'", since)
+			return err
 		}
 
-		userBlacklist, repoBlacklist := buildBlacklists(blacklist)
-
-		csv := io.Discard
-		if csvPath != "" {
-			if err := os.MkdirAll(filepath.Dir(csvPath), 0o755); err != nil {
-				return fmt.Errorf("failed to create csv file: %w", err)
-			}
-			f, err := os.OpenFile(csvPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if reposFile != "" {
+			fileRepos, err := readLines(reposFile)
 			if err != nil {
-				return fmt.Errorf("failed to create csv file: %w", err)
+				return fmt.Errorf("invalid --repo-file: %w", err)
 			}
-			defer f.Close()
-			csv = f
+			repos = append(repos, fileRepos...)
+		}
+		if len(organizations)+len(users)+len(repos) == 0 {
+			return fmt.Errorf("at least one --org, --user, --repo or --repo-file is required")
+		}
+
+		if by != ui.ByUser && by != ui.ByOrg && by != ui.ByRepo && by != ui.ByTeam {
+			return fmt.Errorf("invalid --by: '%s'", by)
+		}
+		if api != orgstats.APIREST && api != orgstats.APIGraphQL {
+			return fmt.Errorf("invalid --api: '%s'", api)
+		}
+
+		sinceT, untilT, err := parsePeriod(since, from, until)
+		if err != nil {
+			return err
+		}
+
+		userBlacklist, repoBlacklist, err := buildBlacklists(blacklist)
+		if err != nil {
+			return fmt.Errorf("invalid --blacklist: %w", err)
+		}
+		userAllowlist, repoAllowlist, err := buildAllowlists(only)
+		if err != nil {
+			return fmt.Errorf("invalid --only: %w", err)
+		}
+		if _, err := orgstats.ParsePatterns(botPatterns); err != nil {
+			return fmt.Errorf("invalid --bot: %w", err)
+		}
+		identities, err := loadIdentities(identitiesPath)
+		if err != nil {
+			return err
+		}
+
+		filter, err := buildRepoFilter()
+		if err != nil {
+			return err
 		}
 
+		csv, closeCSV, err := createCSV(csvPath)
+		if err != nil {
+			return err
+		}
+		defer closeCSV()
+
 		f, err := tea.LogToFile(filepath.Join(os.TempDir(), "org-stats.log"), "org-stats")
 		if err != nil {
 			return err
 		}
 		defer f.Close()
 
-		sinceT := time.Time{}
-		if sinceD > 0 {
-			sinceT = time.Now().UTC().Add(-1 * time.Duration(sinceD))
+		checkpointPath := checkpoint
+		if checkpointPath == "" {
+			dir, err := resolveCacheDir()
+			if err != nil {
+				return err
+			}
+			name := strings.Join(append(append(append([]string{}, organizations...), users...), repos...), "+")
+			checkpointPath = filepath.Join(dir, "checkpoints", strings.ReplaceAll(name, "/", "_")+".json")
 		}
 
-		p := tea.NewProgram(ui.NewInitialModel(
+		if useGit && gitDir == "" {
+			dir, err := resolveCacheDir()
+			if err != nil {
+				return err
+			}
+			gitDir = filepath.Join(dir, "repos")
+		}
+
+		collector := orgstats.NewCollector(
 			client,
-			organization,
-			userBlacklist,
-			repoBlacklist,
-			sinceT,
-			top,
-			includeReviews,
-			excludeForks,
-			csv,
-		))
+			"",
+			orgstats.WithOrgs(organizations...),
+			orgstats.WithUsers(users...),
+			orgstats.WithRepos(repos...),
+			orgstats.WithUserBlacklist(userBlacklist...),
+			orgstats.WithRepoBlacklist(repoBlacklist...),
+			orgstats.WithUserAllowlist(userAllowlist...),
+			orgstats.WithRepoAllowlist(repoAllowlist...),
+			orgstats.WithSince(sinceT),
+			orgstats.WithUntil(untilT),
+			orgstats.WithReviews(includeReviews || reviewMembers || len(reviewTeams) > 0),
+			orgstats.WithReviewMembers(reviewMembers),
+			orgstats.WithReviewTeams(reviewTeams...),
+			orgstats.WithTeams(by == ui.ByTeam),
+			orgstats.WithOnlyTeams(teams...),
+			orgstats.WithPullRequests(includePRs),
+			orgstats.WithReviewDetails(reviewDetails),
+			orgstats.WithIssues(includeIssues),
+			orgstats.WithExcludeForks(excludeForks),
+			orgstats.WithExcludeBots(excludeBots),
+			orgstats.WithBotPatterns(botPatterns...),
+			orgstats.WithIdentities(identities),
+			orgstats.WithRepoFilter(filter),
+			orgstats.WithGitDir(gitDir),
+			orgstats.WithGitToken(token),
+			orgstats.WithAPI(api),
+			orgstats.WithConcurrency(concurrency),
+			orgstats.WithCheckpoint(checkpointPath),
+			orgstats.WithResume(resume),
+			orgstats.WithPeriod(periodFlags(since, from, until)),
+		)
+
+		if dryRun {
+			names, err := collector.Repositories(ctx)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		p := tea.NewProgram(
+			ui.NewInitialModel(ctx, cancel, collector, top, by, csv),
+			tea.WithContext(ctx),
+		)
+		rateLimits.OnWait = func(w githuberrors.Wait) {
+			p.Send(ui.RateLimitMsg(w))
+		}
 		_, err = p.Run()
+		if errors.Is(err, context.Canceled) || errors.Is(err, tea.ErrInterrupted) {
+			// the user asked to quit, which is not an error.
+			return nil
+		}
 		return err
 	},
 }
+
+func buildRepoFilter() (orgstats.RepoFilter, error) {
+	include, err := orgstats.ParsePatterns(includeRepos)
+	if err != nil {
+		return orgstats.RepoFilter{}, fmt.Errorf("invalid --include-repo: %w", err)
+	}
+	exclude, err := orgstats.ParsePatterns(excludeRepos)
+	if err != nil {
+		return orgstats.RepoFilter{}, fmt.Errorf("invalid --exclude-repo: %w", err)
+	}
+	for _, v := range visibilities {
+		switch v {
+		case "public", "private", "internal":
+		default:
+			return orgstats.RepoFilter{}, fmt.Errorf("invalid --visibility: '%s'", v)
+		}
+	}
+	filter := orgstats.RepoFilter{
+		Include:       include,
+		Exclude:       exclude,
+		Topics:        topics,
+		ExcludeTopics: excludeTopics,
+		Languages:     languages,
+		Visibilities:  visibilities,
+	}
+	switch archived {
+	case "include":
+		filter.Archived = orgstats.ArchivedInclude
+	case "exclude":
+		filter.Archived = orgstats.ArchivedExclude
+	case "only":
+		filter.Archived = orgstats.ArchivedOnly
+	default:
+		return orgstats.RepoFilter{}, fmt.Errorf("invalid --archived: '%s'", archived)
+	}
+	if pushedSince != "" {
+		t, err := parseTime(pushedSince)
+		if err != nil {
+			return orgstats.RepoFilter{}, fmt.Errorf("invalid --pushed-since: %w", err)
+		}
+		filter.PushedSince = t
+	}
+	return filter, nil
+}
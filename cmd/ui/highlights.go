@@ -8,18 +8,20 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func NewHighlightsModel(stats orgstats.Stats, top int, includeReviews bool) HighlightsModel {
+func NewHighlightsModel(stats orgstats.Stats, top int, by string, opts orgstats.Options) HighlightsModel {
 	return HighlightsModel{
-		stats:          stats,
-		top:            top,
-		includeReviews: includeReviews,
+		stats: stats,
+		top:   top,
+		by:    by,
+		opts:  opts,
 	}
 }
 
 type HighlightsModel struct {
-	stats          orgstats.Stats
-	top            int
-	includeReviews bool
+	stats orgstats.Stats
+	top   int
+	by    string
+	opts  orgstats.Options
 }
 
 func (m HighlightsModel) Init() tea.Cmd {
@@ -32,6 +34,15 @@ func (m HighlightsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m HighlightsModel) View() string {
 	var b bytes.Buffer
-	_ = highlights.Write(&b, m.stats, m.top, m.includeReviews)
+	write := highlights.WriteWithOptions
+	switch m.by {
+	case ByOrg:
+		write = highlights.WriteByOrg
+	case ByRepo:
+		write = highlights.WriteByRepo
+	case ByTeam:
+		write = highlights.WriteByTeam
+	}
+	_ = write(&b, m.stats, m.top, m.opts)
 	return b.String()
 }
@@ -5,27 +5,44 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/org-stats/csv"
+	"github.com/caarlos0/org-stats/github_errors"
 	"github.com/caarlos0/org-stats/orgstats"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/google/go-github/v39/github"
 )
 
 type errMsg struct{ error }
 
+// RateLimitMsg tells the UI requests are paused because of a rate limit.
+type RateLimitMsg githuberrors.Wait
+
+// How the results can be broken down.
+const (
+	// ByUser merges the stats of all organizations.
+	ByUser = "user"
+	// ByOrg shows the stats of each organization on its own.
+	ByOrg = "org"
+	// ByRepo shows the contributors of each repository.
+	ByRepo = "repo"
+	// ByTeam shows the stats rolled up per organization team.
+	ByTeam = "team"
+)
+
 // NewInitialModel creates a new InitialModel with required fields.
+//
+// The given cancel function is called when the user quits, so the
+// gathering running in ctx stops as well.
 func NewInitialModel(
-	client *github.Client,
-	org string,
-	userBlacklist, repoBlacklist []string,
-	since time.Time,
+	ctx context.Context,
+	cancel context.CancelFunc,
+	collector *orgstats.Collector,
 	top int,
-	includeReviewStats bool,
-	excludeForks bool,
+	by string,
 	csv io.Writer,
 ) InitialModel {
 	s := spinner.New()
@@ -33,17 +50,14 @@ func NewInitialModel(
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return InitialModel{
-		client:             client,
-		org:                org,
-		userBlacklist:      userBlacklist,
-		repoBlacklist:      repoBlacklist,
-		since:              since,
-		includeReviewStats: includeReviewStats,
-		excludeForks:       excludeForks,
-		top:                top,
-		spinner:            s,
-		csv:                csv,
-		loading:            true,
+		ctx:       ctx,
+		cancel:    cancel,
+		collector: collector,
+		top:       top,
+		by:        by,
+		spinner:   s,
+		csv:       csv,
+		loading:   true,
 	}
 }
 
@@ -54,28 +68,18 @@ type InitialModel struct {
 	loading  bool
 	quitting bool
 
-	client             *github.Client
-	org                string
-	userBlacklist      []string
-	repoBlacklist      []string
-	since              time.Time
-	includeReviewStats bool
-	excludeForks       bool
-	top                int
-	csv                io.Writer
+	ctx       context.Context
+	cancel    context.CancelFunc
+	collector *orgstats.Collector
+	top       int
+	by        string
+	csv       io.Writer
+	rateLimit RateLimitMsg
 }
 
 func (m InitialModel) Init() tea.Cmd {
 	return tea.Batch(
-		getStats(
-			m.client,
-			m.org,
-			m.userBlacklist,
-			m.repoBlacklist,
-			m.since,
-			m.includeReviewStats,
-			m.excludeForks,
-		),
+		getStats(m.ctx, m.collector),
 		m.spinner.Tick,
 	)
 }
@@ -83,20 +87,28 @@ func (m InitialModel) Init() tea.Cmd {
 func (m InitialModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case errMsg:
+		if m.quitting {
+			return m, nil
+		}
 		m.loading = false
 		m.err = msg.error
 		return m, nil
+	case RateLimitMsg:
+		m.rateLimit = msg
+		return m, nil
 	case gotResults:
 		log.Println("got results", len(msg.stats.Logins()), "logins")
-		highlights := NewHighlightsModel(msg.stats, m.top, m.includeReviewStats)
+		opts := m.collector.Options()
+		highlights := NewHighlightsModel(msg.stats, m.top, m.by, opts)
 		return highlights, tea.Batch(
-			writeCsv(m.csv, msg.stats, m.includeReviewStats),
+			writeCsv(m.csv, msg.stats, m.by, opts),
 			highlights.Init(),
 		)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
 			m.quitting = true
+			m.cancel()
 			return m, tea.Quit
 		}
 	default:
@@ -111,7 +123,19 @@ func (m InitialModel) View() string {
 	if m.err != nil {
 		return m.err.Error()
 	}
-	str := fmt.Sprintf("\n\n   %s Gathering data for %s... press q to quit\n\n", m.spinner.View(), m.org)
+	str := fmt.Sprintf(
+		"\n\n   %s Gathering data for %s (%s)... press q to quit\n\n",
+		m.spinner.View(),
+		strings.Join(m.collector.Sources(), ", "),
+		orgstats.NewStatsBetween(m.collector.Options().Since, m.collector.Options().Until).Period(),
+	)
+	if time.Now().Before(m.rateLimit.Until) {
+		kind := "rate limit"
+		if m.rateLimit.Secondary {
+			kind = "secondary rate limit"
+		}
+		str += fmt.Sprintf("   waiting for %s until %s\n\n", kind, m.rateLimit.Until.Format("15:04"))
+	}
 	if m.quitting {
 		return str + "\n"
 	}
@@ -122,25 +146,9 @@ type gotResults struct {
 	stats orgstats.Stats
 }
 
-func getStats(
-	client *github.Client,
-	org string,
-	userBlacklist, repoBlacklist []string,
-	since time.Time,
-	includeReviews bool,
-	excludeForks bool,
-) tea.Cmd {
+func getStats(ctx context.Context, collector *orgstats.Collector) tea.Cmd {
 	return func() tea.Msg {
-		stats, err := orgstats.Gather(
-			context.Background(),
-			client,
-			org,
-			userBlacklist,
-			repoBlacklist,
-			since,
-			includeReviews,
-			excludeForks,
-		)
+		stats, err := collector.Gather(ctx)
 		if err != nil {
 			return errMsg{err}
 		}
@@ -148,9 +156,18 @@ func getStats(
 	}
 }
 
-func writeCsv(w io.Writer, stats orgstats.Stats, includeReviews bool) tea.Cmd {
+func writeCsv(w io.Writer, stats orgstats.Stats, by string, opts orgstats.Options) tea.Cmd {
 	return func() tea.Msg {
-		if err := csv.Write(w, stats, includeReviews); err != nil {
+		write := csv.WriteWithOptions
+		switch by {
+		case ByOrg:
+			write = csv.WriteByOrg
+		case ByRepo:
+			write = csv.WriteByRepo
+		case ByTeam:
+			write = csv.WriteByTeam
+		}
+		if err := write(w, stats, opts); err != nil {
 			return errMsg{err}
 		}
 		return tea.Quit
@@ -6,40 +6,172 @@ import (
 	"io"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/caarlos0/org-stats/orgstats"
 )
 
+// Write writes the stats of each user, with a reviews column if
+// includeReviews. See WriteWithOptions for the other stats.
 func Write(w io.Writer, s orgstats.Stats, includeReviews bool) error {
+	return WriteWithOptions(w, s, orgstats.Options{IncludeReviews: includeReviews})
+}
+
+// WriteWithOptions writes the stats of each user, with a column for each
+// stat gathered according to the options.
+func WriteWithOptions(w io.Writer, s orgstats.Stats, opts orgstats.Options) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if err := cw.Write(headers(opts)); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	if err := writeRecords(cw, nil, s, opts); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteByOrg writes the stats of each user, for each organization, with a
+// column for each stat gathered according to the options.
+func WriteByOrg(w io.Writer, s orgstats.Stats, opts orgstats.Options) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if err := cw.Write(append([]string{"org"}, headers(opts)...)); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	for _, org := range s.Orgs() {
+		if err := writeRecords(cw, []string{org}, s.ForOrg(org), opts); err != nil {
+			return err
+		}
+	}
+	return cw.Error()
+}
+
+// WriteByRepo writes the stats of each user, for each repository, with a
+// column for each stat gathered according to the options, but reviews, see
+// Stats.ForRepo.
+func WriteByRepo(w io.Writer, s orgstats.Stats, opts orgstats.Options) error {
 	cw := csv.NewWriter(w)
 	defer cw.Flush()
 
+	opts.IncludeReviews = false
+	if err := cw.Write(append([]string{"repo"}, headers(opts)...)); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	for _, repo := range s.Repos() {
+		if err := writeRecords(cw, []string{repo}, s.ForRepo(repo), opts); err != nil {
+			return err
+		}
+	}
+	return cw.Error()
+}
+
+// WriteByTeam writes the stats rolled up per team, see Stats.ByTeam, with
+// a column for each stat gathered according to the options.
+func WriteByTeam(w io.Writer, s orgstats.Stats, opts orgstats.Options) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := headers(opts)
+	header[0] = "team"
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	if err := writeRecords(cw, nil, s.ByTeam(), opts); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func headers(opts orgstats.Options) []string {
 	headers := []string{"login", "commits", "lines-added", "lines-removed"}
-	if includeReviews {
+	if opts.IncludeReviews {
 		headers = append(headers, "reviews")
 	}
-	if err := cw.Write(headers); err != nil {
-		return fmt.Errorf("failed to write csv: %w", err)
+	if opts.IncludePullRequests {
+		headers = append(headers,
+			"prs-opened",
+			"prs-merged",
+			"prs-closed",
+			"median-hours-to-merge",
+			"median-pr-size",
+		)
+	}
+	if opts.ReviewDetails {
+		headers = append(headers,
+			"approvals",
+			"changes-requested",
+			"review-comments",
+			"median-hours-to-first-review",
+		)
 	}
+	if opts.IncludeIssues {
+		headers = append(headers, "issues-opened", "issues-closed", "issue-comments")
+	}
+	return append(headers, "from", "until")
+}
+
+// period returns the inclusive dates the stats cover, empty if unbounded.
+func period(s orgstats.Stats) (string, string) {
+	const layout = "2006-01-02"
+	var from, until string
+	if !s.Since().IsZero() {
+		from = s.Since().Format(layout)
+	}
+	if !s.Until().IsZero() {
+		until = s.Until().Add(-time.Nanosecond).Format(layout)
+	}
+	return from, until
+}
 
+// writeRecords writes a record for each login, starting with the given
+// prefix columns.
+func writeRecords(cw *csv.Writer, prefix []string, s orgstats.Stats, opts orgstats.Options) error {
 	logins := s.Logins()
 	sort.Strings(logins)
+	from, until := period(s)
 
 	for _, login := range logins {
 		stat := s.For(login)
-		record := []string{
+		record := append(append([]string{}, prefix...),
 			login,
 			strconv.Itoa(stat.Commits),
 			strconv.Itoa(stat.Additions),
 			strconv.Itoa(stat.Deletions),
-		}
-		if includeReviews {
+		)
+		if opts.IncludeReviews {
 			record = append(record, strconv.Itoa(stat.Reviews))
 		}
+		if opts.IncludePullRequests {
+			record = append(record,
+				strconv.Itoa(stat.PullRequests),
+				strconv.Itoa(stat.MergedPullRequests),
+				strconv.Itoa(stat.ClosedPullRequests),
+				strconv.FormatFloat(stat.MedianTimeToMerge.Hours(), 'f', 1, 64),
+				strconv.Itoa(stat.MedianPullRequestSize),
+			)
+		}
+		if opts.ReviewDetails {
+			record = append(record,
+				strconv.Itoa(stat.Approvals),
+				strconv.Itoa(stat.ChangesRequested),
+				strconv.Itoa(stat.ReviewComments),
+				strconv.FormatFloat(stat.MedianTimeToFirstReview.Hours(), 'f', 1, 64),
+			)
+		}
+		if opts.IncludeIssues {
+			record = append(record,
+				strconv.Itoa(stat.IssuesOpened),
+				strconv.Itoa(stat.IssuesClosed),
+				strconv.Itoa(stat.IssueComments),
+			)
+		}
+		record = append(record, from, until)
 		if err := cw.Write(record); err != nil {
 			return fmt.Errorf("failed to write csv: %w", err)
 		}
 	}
-
-	return cw.Error()
+	return nil
 }
@@ -0,0 +1,115 @@
+package githuberrors
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Budget spreads requests evenly over each rate limit window, instead of
+// burning the whole quota at once and then sitting idle until it resets.
+//
+// Each rate limit resource (core, search, graphql) is tracked on its own,
+// as GitHub limits them separately.
+type Budget struct {
+	// Reserve is the fraction, between 0 and 1, of each quota left
+	// untouched for other tools sharing the same token.
+	Reserve float64
+
+	mu     sync.Mutex
+	quotas map[string]*quota
+}
+
+type quota struct {
+	limit, remaining int64
+	reset            time.Time
+	last             time.Time
+}
+
+// NewBudget returns a Budget reserving the given fraction of each quota.
+func NewBudget(reserve float64) *Budget {
+	return &Budget{
+		Reserve: reserve,
+		quotas:  map[string]*quota{},
+	}
+}
+
+// take reserves a request for the given resource, returning how long to
+// wait before making it. If the usable quota is exhausted, the returned Wait
+// tells until when.
+func (b *Budget) take(resource string) (time.Duration, *Wait) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	q := b.quotas[resource]
+	if q == nil || !q.reset.After(now) {
+		// nothing known about the current window yet.
+		return 0, nil
+	}
+
+	reserved := int64(math.Ceil(float64(q.limit) * b.Reserve))
+	usable := q.remaining - reserved
+	if usable <= 0 {
+		return q.reset.Sub(now), &Wait{Until: q.reset}
+	}
+
+	at := q.last.Add(q.reset.Sub(now) / time.Duration(usable))
+	if at.Before(now) {
+		at = now
+	}
+	q.last = at
+	q.remaining--
+	return at.Sub(now), nil
+}
+
+// update records the quota reported by the given response.
+func (b *Budget) update(resource string, resp *http.Response) {
+	limit, ok := httpHeaderIntValue(resp.Header, HeaderXRateLimitLimit)
+	if !ok {
+		return
+	}
+	remaining, ok := httpHeaderIntValue(resp.Header, HeaderXRateLimitRemaining)
+	if !ok {
+		return
+	}
+	reset := parseXRateLimitReset(resp)
+	if reset == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.quotas == nil {
+		b.quotas = map[string]*quota{}
+	}
+	q := b.quotas[resource]
+	if q == nil || reset.After(q.reset) {
+		// a new window.
+		b.quotas[resource] = &quota{
+			limit:     limit,
+			remaining: remaining,
+			reset:     *reset,
+		}
+		return
+	}
+	// responses may arrive out of order, keep the most conservative count.
+	if remaining < q.remaining {
+		q.remaining = remaining
+	}
+}
+
+// resourceFor returns which rate limit resource the request counts against.
+func resourceFor(req *http.Request) string {
+	switch path := req.URL.Path; {
+	case strings.Contains(path, "/search/"):
+		return "search"
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	default:
+		return "core"
+	}
+}
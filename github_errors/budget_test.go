@@ -0,0 +1,45 @@
+package githuberrors
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestBudget(t *testing.T) {
+	reset := time.Now().Add(10 * time.Second)
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set(HeaderXRateLimitLimit, "100")
+	resp.Header.Set(HeaderXRateLimitRemaining, "30")
+	resp.Header.Set(HeaderXRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+
+	b := NewBudget(0.2)
+	b.update("core", resp)
+
+	is := is.New(t)
+
+	// unknown resources are not throttled.
+	d, w := b.take("search")
+	is.Equal(d, time.Duration(0))
+	is.Equal(w, nil)
+
+	// 10 usable requests over the remaining window: ~1s apart.
+	d, w = b.take("core")
+	is.Equal(w, nil)
+	is.True(d < 100*time.Millisecond)
+	d, w = b.take("core")
+	is.Equal(w, nil)
+	is.True(d > 500*time.Millisecond && d < 1500*time.Millisecond)
+
+	// once only the reserve is left, requests wait for the reset.
+	for i := 0; i < 8; i++ {
+		_, w = b.take("core")
+		is.Equal(w, nil)
+	}
+	_, w = b.take("core")
+	is.True(w != nil)
+	is.Equal(w.Until.Unix(), reset.Unix())
+}
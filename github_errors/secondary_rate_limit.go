@@ -20,6 +20,7 @@ const (
 	HeaderXRateLimitRemaining                 = "x-ratelimit-remaining"
 	HeaderRetryAfter                          = "retry-after"
 	HeaderXRateLimitReset                     = "x-ratelimit-reset"
+	HeaderXRateLimitLimit                     = "x-ratelimit-limit"
 )
 
 func IsSecondaryRateLimitError(r *github.Response) (bool, *SecondaryRateLimitError) {
@@ -66,7 +67,7 @@ func (s SecondaryRateLimitBody) IsSecondaryRateLimit() bool {
 // isSecondaryRateLimit checks whether the response is a legitimate secondary rate limit.
 // it is used to avoid handling primary rate limits and authentic HTTP Forbidden (403) responses.
 func isSecondaryRateLimit(resp *http.Response) (bool, *SecondaryRateLimitBody) {
-	if resp.StatusCode != http.StatusForbidden {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
 		return false, nil
 	}
 
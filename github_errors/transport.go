@@ -0,0 +1,203 @@
+package githuberrors
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// Wait describes a pause caused by a rate limit.
+type Wait struct {
+	// Until is when requests are resumed.
+	Until time.Time
+	// Secondary is true if the pause was caused by a secondary rate limit.
+	Secondary bool
+}
+
+// Transport is an http.RoundTripper that transparently waits and retries
+// when GitHub answers with a primary or secondary rate limit.
+//
+// All requests going through the same Transport wait together, so
+// concurrent callers don't keep hitting a limit one already found.
+type Transport struct {
+	// Base is the RoundTripper used to make the requests.
+	Base http.RoundTripper
+	// OnWait, if set, is called every time requests are paused.
+	OnWait func(Wait)
+	// Budget, if set, paces requests so the quota lasts until it resets.
+	Budget *Budget
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// NewTransport returns a Transport wrapping the given RoundTripper.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	resource := resourceFor(req)
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			return nil, err
+		}
+		if err := t.throttle(ctx, resource); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+			if req.Body != nil && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := t.base().RoundTrip(r)
+		if err != nil {
+			return resp, err
+		}
+		if t.Budget != nil {
+			t.Budget.update(resource, resp)
+		}
+
+		if until, ok := primaryLimitReset(resp); ok {
+			if resp.StatusCode < 300 {
+				// this was the last request allowed: hold the response until
+				// the limit resets, otherwise go-github would refuse to make
+				// the next request on its own.
+				if err := t.pause(ctx, Wait{Until: until}); err != nil {
+					resp.Body.Close()
+					return nil, err
+				}
+				return resp, nil
+			}
+			if req.Body != nil && req.GetBody == nil {
+				return resp, nil // can't retry
+			}
+			drain(resp)
+			if err := t.pause(ctx, Wait{Until: until}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if ok, secondary := IsSecondaryRateLimitError(&github.Response{Response: resp}); ok {
+			if req.Body != nil && req.GetBody == nil {
+				return resp, nil // can't retry
+			}
+			until := time.Now().Add(10 * time.Second)
+			if secondary.RetryAfter != nil && secondary.RetryAfter.After(time.Now()) {
+				until = *secondary.RetryAfter
+			}
+			drain(resp)
+			if err := t.pause(ctx, Wait{Until: until, Secondary: true}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return resp, nil
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// pause makes all requests wait until the given time.
+func (t *Transport) pause(ctx context.Context, w Wait) error {
+	t.mu.Lock()
+	if w.Until.After(t.until) {
+		t.until = w.Until
+	}
+	t.mu.Unlock()
+
+	if w.Secondary {
+		log.Printf("hit secondary rate limit, waiting until %v", w.Until)
+	} else {
+		log.Printf("hit rate limit, waiting until %v", w.Until)
+	}
+	if t.OnWait != nil {
+		t.OnWait(w)
+	}
+	return t.wait(ctx)
+}
+
+// throttle waits for the Budget to allow a request to the given resource.
+func (t *Transport) throttle(ctx context.Context, resource string) error {
+	if t.Budget == nil {
+		return nil
+	}
+	for {
+		d, exhausted := t.Budget.take(resource)
+		if exhausted == nil {
+			return sleep(ctx, d)
+		}
+		log.Printf("%s rate limit budget exhausted, waiting until %v", resource, exhausted.Until)
+		if t.OnWait != nil {
+			t.OnWait(*exhausted)
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// wait blocks while requests are paused, or until the context is done.
+func (t *Transport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	until := t.until
+	t.mu.Unlock()
+	return sleep(ctx, time.Until(until))
+}
+
+// sleep pauses for the given duration, returning early with the context
+// error if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// primaryLimitReset checks whether the response exhausted the primary rate
+// limit, and if so, when it resets.
+func primaryLimitReset(resp *http.Response) (time.Time, bool) {
+	remaining, ok := httpHeaderIntValue(resp.Header, HeaderXRateLimitRemaining)
+	if !ok || remaining > 0 {
+		return time.Time{}, false
+	}
+	until := time.Now().Add(5 * time.Second)
+	if reset := parseXRateLimitReset(resp); reset != nil && reset.After(time.Now()) {
+		until = *reset
+	}
+	return until, true
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
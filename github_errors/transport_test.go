@@ -0,0 +1,67 @@
+package githuberrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTransportRetriesSecondaryRateLimit(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set(HeaderRetryAfter, "1")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit"}`)
+			return
+		}
+		fmt.Fprint(w, `ok`)
+	}))
+	t.Cleanup(srv.Close)
+
+	var waits []Wait
+	tr := NewTransport(http.DefaultTransport)
+	tr.OnWait = func(w Wait) { waits = append(waits, w) }
+
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+
+	is := is.New(t)
+	is.NoErr(err)
+	defer resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)
+	is.Equal(calls, 2)
+	is.Equal(len(waits), 1)
+	is.True(waits[0].Secondary)
+}
+
+func TestTransportWaitsPrimaryRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderXRateLimitRemaining, "0")
+		w.Header().Set(HeaderXRateLimitReset, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	var waits []Wait
+	tr := NewTransport(http.DefaultTransport)
+	tr.OnWait = func(w Wait) { waits = append(waits, w) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	_, err := (&http.Client{Transport: tr}).Do(req)
+
+	is := is.New(t)
+	is.True(errors.Is(err, context.Canceled))
+	is.Equal(len(waits), 1)
+	is.True(!waits[0].Secondary)
+	is.True(waits[0].Until.After(time.Now().Add(50 * time.Minute)))
+}
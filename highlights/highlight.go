@@ -3,12 +3,178 @@ package highlights
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/org-stats/orgstats"
 	"github.com/charmbracelet/lipgloss"
 )
 
+var titleStyle = lipgloss.NewStyle().
+	Bold(true).
+	MarginTop(2)
+
+var headerStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{
+		Dark:  "#BD7EFC",
+		Light: "#7D56F4",
+	}).
+	MarginTop(1).
+	Underline(true)
+
+var bodyStyle = lipgloss.NewStyle().
+	MarginLeft(2)
+
+// Write writes the champions of each category, including reviews if
+// includeReviews. See WriteWithOptions for the other categories.
 func Write(w io.Writer, s orgstats.Stats, top int, includeReviews bool) error {
+	return WriteWithOptions(w, s, top, orgstats.Options{IncludeReviews: includeReviews})
+}
+
+// WriteWithOptions writes the champions of each category gathered
+// according to the options.
+func WriteWithOptions(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
+	if err := writePeriod(w, s); err != nil {
+		return err
+	}
+	if err := writeChampions(w, s, top, opts); err != nil {
+		return err
+	}
+	return writeUnavailable(w, s)
+}
+
+// WriteByOrg writes the champions of each category gathered according to
+// the options, for each organization.
+func WriteByOrg(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
+	if err := writePeriod(w, s); err != nil {
+		return err
+	}
+	for _, org := range s.Orgs() {
+		if _, err := fmt.Fprintln(
+			w,
+			titleStyle.Render(org),
+		); err != nil {
+			return err
+		}
+		if err := writeChampions(w, s.ForOrg(org), top, opts); err != nil {
+			return err
+		}
+	}
+	return writeUnavailable(w, s)
+}
+
+// WriteByTeam writes the champion teams of each category gathered
+// according to the options, see Stats.ByTeam.
+func WriteByTeam(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
+	if err := writePeriod(w, s); err != nil {
+		return err
+	}
+	if err := writeHumanChampions(w, s.ByTeam(), top, opts); err != nil {
+		return err
+	}
+	return writeUnavailable(w, s)
+}
+
+// WriteByRepo writes the top contributors of each repository, and its top
+// reviewers if gathered with review details, see Stats.ForRepo.
+func WriteByRepo(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
+	if err := writePeriod(w, s); err != nil {
+		return err
+	}
+	for _, repo := range s.Repos() {
+		if _, err := fmt.Fprintln(
+			w,
+			headerStyle.Render(repo+" top contributors are:"),
+		); err != nil {
+			return err
+		}
+		repoStats := s.ForRepo(repo).Humans()
+		contributors := orgstats.Sort(repoStats, orgstats.ExtractChanges)
+		j := top
+		if len(contributors) < j {
+			j = len(contributors)
+		}
+		for i := 0; i < j && contributors[i].Value > 0; i++ {
+			stat := repoStats.For(contributors[i].Key)
+			if _, err := fmt.Fprintln(w,
+				bodyStyle.Render(
+					fmt.Sprintf(
+						"%s %s with %d lines added, %d lines removed and %d commits",
+						emojiForPos(i),
+						contributors[i].Key,
+						stat.Additions,
+						stat.Deletions,
+						stat.Commits,
+					),
+				),
+			); err != nil {
+				return err
+			}
+		}
+		if opts.ReviewDetails {
+			if err := writeRepoReviewers(w, repo, repoStats, top); err != nil {
+				return err
+			}
+		}
+	}
+	return writeUnavailable(w, s)
+}
+
+// writeRepoReviewers writes the reviewers with the most approvals, change
+// requests and review comments on the repository.
+func writeRepoReviewers(w io.Writer, repo string, s orgstats.Stats, top int) error {
+	reviewers := orgstats.Sort(s, func(st orgstats.Stat) int {
+		return st.Approvals + st.ChangesRequested + st.ReviewComments
+	})
+	if _, err := fmt.Fprintln(
+		w,
+		headerStyle.Render(repo+" top reviewers are:"),
+	); err != nil {
+		return err
+	}
+	for i := 0; i < top && i < len(reviewers) && reviewers[i].Value > 0; i++ {
+		stat := s.For(reviewers[i].Key)
+		if _, err := fmt.Fprintln(w,
+			bodyStyle.Render(
+				fmt.Sprintf(
+					"%s %s with %d approvals, %d change requests and %d review comments",
+					emojiForPos(i),
+					reviewers[i].Key,
+					stat.Approvals,
+					stat.ChangesRequested,
+					stat.ReviewComments,
+				),
+			),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePeriod(w io.Writer, s orgstats.Stats) error {
+	_, err := fmt.Fprintln(
+		w,
+		titleStyle.Render("Stats for "+s.Period()),
+	)
+	return err
+}
+
+// writeChampions writes the human champions of each category, followed by
+// the automation section, if there were any bots.
+func writeChampions(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
+	if err := writeHumanChampions(w, s.Humans(), top, opts); err != nil {
+		return err
+	}
+	if err := writeAutomation(w, s, top); err != nil {
+		return err
+	}
+	return writeUnattributed(w, s)
+}
+
+func writeHumanChampions(w io.Writer, s orgstats.Stats, top int, opts orgstats.Options) error {
 	data := []statHighlight{
 		{
 			stats:  orgstats.Sort(s, orgstats.ExtractCommits),
@@ -25,7 +191,7 @@ func Write(w io.Writer, s orgstats.Stats, top int, includeReviews bool) error {
 		},
 	}
 
-	if includeReviews {
+	if opts.IncludeReviews {
 		data = append(data, statHighlight{
 			stats:  orgstats.Sort(s, orgstats.Reviews),
 			trophy: "Pull Requests Reviewed",
@@ -33,17 +199,57 @@ func Write(w io.Writer, s orgstats.Stats, top int, includeReviews bool) error {
 		})
 	}
 
-	var headerStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.AdaptiveColor{
-			Dark:  "#BD7EFC",
-			Light: "#7D56F4",
-		}).
-		MarginTop(1).
-		Underline(true)
+	if opts.IncludePullRequests {
+		data = append(data, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractPullRequests),
+			trophy: "Pull Requests Opened",
+			kind:   "pull requests opened",
+		}, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractMergedPullRequests),
+			trophy: "Pull Requests Merged",
+			kind:   "pull requests merged",
+		}, statHighlight{
+			stats:  lowest(s, orgstats.ExtractMedianTimeToMerge, having(s, orgstats.ExtractMergedPullRequests)),
+			trophy: "Fastest Merges",
+			format: func(v int) string { return "a median of " + minutes(v) + " to merge" },
+		}, statHighlight{
+			stats:  lowest(s, orgstats.ExtractMedianPullRequestSize, having(s, orgstats.ExtractPullRequests)),
+			trophy: "Smallest Pull Requests",
+			format: func(v int) string { return fmt.Sprintf("a median of %d lines changed", v) },
+		})
+	}
+
+	if opts.ReviewDetails {
+		data = append(data, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractApprovals),
+			trophy: "Approvals",
+			kind:   "approvals",
+		}, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractChangesRequested),
+			trophy: "Changes Requested",
+			kind:   "change requests",
+		}, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractReviewComments),
+			trophy: "Review Comments",
+			kind:   "review comments",
+		}, statHighlight{
+			stats:  lowest(s, orgstats.ExtractMedianTimeToFirstReview, reviewedFirst(s)),
+			trophy: "Fastest Reviewers",
+			format: func(v int) string { return "a median of " + minutes(v) + " to first review" },
+		})
+	}
 
-	var bodyStyle = lipgloss.NewStyle().
-		MarginLeft(2)
+	if opts.IncludeIssues {
+		data = append(data, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractIssuesOpened),
+			trophy: "Issues Opened",
+			kind:   "issues opened",
+		}, statHighlight{
+			stats:  orgstats.Sort(s, orgstats.ExtractTriage),
+			trophy: "Triage",
+			kind:   "issues closed and comments",
+		})
+	}
 
 	// TODO: handle no results for a given topic
 	for _, d := range data {
@@ -58,14 +264,17 @@ func Write(w io.Writer, s orgstats.Stats, top int, includeReviews bool) error {
 			j = len(d.stats)
 		}
 		for i := 0; i < j; i++ {
+			value := fmt.Sprintf("%d %s", d.stats[i].Value, d.kind)
+			if d.format != nil {
+				value = d.format(d.stats[i].Value)
+			}
 			if _, err := fmt.Fprintln(w,
 				bodyStyle.Render(
 					fmt.Sprintf(
-						"%s %s with %d %s!",
+						"%s %s with %s!",
 						emojiForPos(i),
 						d.stats[i].Key,
-						d.stats[i].Value,
-						d.kind,
+						value,
 					),
 				),
 			); err != nil {
@@ -76,6 +285,104 @@ func Write(w io.Writer, s orgstats.Stats, top int, includeReviews bool) error {
 	return nil
 }
 
+// writeAutomation writes the bots with most changes, and how much of the
+// total changes they account for.
+func writeAutomation(w io.Writer, s orgstats.Stats, top int) error {
+	bots := orgstats.Sort(s.Bots(), orgstats.ExtractChanges)
+	if len(bots) == 0 {
+		return nil
+	}
+
+	var total, automated int
+	for _, pair := range orgstats.Sort(s, orgstats.ExtractChanges) {
+		total += pair.Value
+	}
+	for _, pair := range bots {
+		automated += pair.Value
+	}
+	share := 0.0
+	if total > 0 {
+		share = float64(automated) / float64(total) * 100
+	}
+
+	if _, err := fmt.Fprintln(
+		w,
+		headerStyle.Render("Automation:"),
+	); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(
+		w,
+		bodyStyle.Render(fmt.Sprintf("bots account for %.1f%% of the lines changed", share)),
+	); err != nil {
+		return err
+	}
+	j := top
+	if len(bots) < j {
+		j = len(bots)
+	}
+	for i := 0; i < j; i++ {
+		stat := s.For(bots[i].Key)
+		if _, err := fmt.Fprintln(w,
+			bodyStyle.Render(
+				fmt.Sprintf(
+					"\U0001f916 %s with %d lines added, %d lines removed and %d commits",
+					bots[i].Key,
+					stat.Additions,
+					stat.Deletions,
+					stat.Commits,
+				),
+			),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUnattributed writes the commits that could not be attributed to
+// anyone, if any.
+func writeUnattributed(w io.Writer, s orgstats.Stats) error {
+	stat := s.For(orgstats.Unattributed)
+	if stat.Commits == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(
+		w,
+		headerStyle.Render("Unattributed:"),
+	); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(
+		w,
+		bodyStyle.Render(fmt.Sprintf(
+			"%d commits, with %d lines added and %d lines removed, could not be attributed to anyone",
+			stat.Commits,
+			stat.Additions,
+			stat.Deletions,
+		)),
+	)
+	return err
+}
+
+func writeUnavailable(w io.Writer, s orgstats.Stats) error {
+	unavailable := s.Unavailable()
+	if len(unavailable) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(
+		w,
+		headerStyle.Render("Stats unavailable for:"),
+	); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(
+		w,
+		bodyStyle.Render(strings.Join(unavailable, ", ")),
+	)
+	return err
+}
+
 func emojiForPos(pos int) string {
 	emojis := []string{"\U0001f3c6", "\U0001f948", "\U0001f949"}
 	if pos < len(emojis) {
@@ -88,4 +395,47 @@ type statHighlight struct {
 	stats  []orgstats.StatPair
 	trophy string
 	kind   string
+	// format, if set, formats the value instead of kind.
+	format func(value int) string
+}
+
+// lowest returns the users has returns true for, such as the ones that
+// merged pull requests, sorted by the lowest value extracted, such as the
+// median time to merge.
+func lowest(s orgstats.Stats, extract orgstats.Extract, has func(login string) bool) []orgstats.StatPair {
+	logins := s.Logins()
+	sort.Strings(logins)
+	var result []orgstats.StatPair
+	for _, login := range logins {
+		if has(login) {
+			result = append(result, orgstats.StatPair{Key: login, Value: extract(s.For(login))})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
+
+// having returns whether a user has any of what extract extracts.
+func having(s orgstats.Stats, extract orgstats.Extract) func(login string) bool {
+	return func(login string) bool {
+		return extract(s.For(login)) > 0
+	}
+}
+
+// reviewedFirst returns whether a user reviewed any pull request, and thus
+// has a time to first review, even if it is zero.
+func reviewedFirst(s orgstats.Stats) func(login string) bool {
+	return func(login string) bool {
+		return len(s.ReviewsFor(login)) > 0
+	}
+}
+
+// minutes formats the given minutes as a duration, as in 1h30m.
+func minutes(v int) string {
+	if v == 0 {
+		return "less than a minute"
+	}
+	return strings.TrimSuffix((time.Duration(v) * time.Minute).String(), "0s")
 }
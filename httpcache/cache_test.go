@@ -0,0 +1,53 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTransport(t *testing.T) {
+	var hits, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(100-hits))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "hello")
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: New(t.TempDir(), http.DefaultTransport)}
+	get := func() (*http.Response, string) {
+		t.Helper()
+		resp, err := client.Get(srv.URL)
+		is.New(t).NoErr(err)
+		defer resp.Body.Close()
+		bts, err := io.ReadAll(resp.Body)
+		is.New(t).NoErr(err)
+		return resp, string(bts)
+	}
+
+	is := is.New(t)
+
+	resp, body := get()
+	is.Equal(resp.StatusCode, http.StatusOK)
+	is.Equal(body, "hello")
+	is.Equal(notModified, 0)
+
+	resp, body = get()
+	is.Equal(resp.StatusCode, http.StatusOK)
+	is.Equal(resp.Status, "200 OK")
+	is.Equal(body, "hello")
+	is.Equal(notModified, 1)
+	is.Equal(resp.Header.Get("X-RateLimit-Remaining"), "98") // fresh header
+	is.Equal(hits, 2)
+}
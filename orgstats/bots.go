@@ -0,0 +1,57 @@
+package orgstats
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// isBot reports whether the contributor is an automation account: either
+// a GitHub App, a login ending in '[bot]', or one matching any of the given
+// patterns.
+func isBot(author *github.Contributor, patterns []Pattern) bool {
+	login := author.GetLogin()
+	return author.GetType() == "Bot" ||
+		strings.HasSuffix(strings.ToLower(login), "[bot]") ||
+		matchAny(patterns, login)
+}
+
+// IsBot reports whether the given login was classified as a bot.
+func (s Stats) IsBot(login string) bool {
+	return s.bots[login]
+}
+
+// Humans returns the stats without bots nor unattributed commits.
+func (s Stats) Humans() Stats {
+	return s.filter(func(login string) bool { return !s.bots[login] && login != Unattributed })
+}
+
+// Bots returns the stats of bots only.
+func (s Stats) Bots() Stats {
+	return s.filter(func(login string) bool { return s.bots[login] })
+}
+
+// filter returns the stats of the logins keep returns true for.
+func (s Stats) filter(keep func(login string) bool) Stats {
+	result := s
+	result.data = make(map[string]Stat)
+	for login, stat := range s.data {
+		if keep(login) {
+			result.data[login] = stat
+		}
+	}
+	result.orgs = make(map[string]map[string]Stat)
+	for org, data := range s.orgs {
+		result.orgs[org] = make(map[string]Stat)
+		for login, stat := range data {
+			if keep(login) {
+				result.orgs[org][login] = stat
+			}
+		}
+	}
+	result.repos = s.reposFilter(func(_, login string) bool { return keep(login) })
+	result.pulls = s.pullsFilter(func(pr PullRequest) bool { return keep(pr.Author) })
+	result.reviews = s.reviewsFilter(func(review Review) bool { return keep(review.Reviewer) })
+	result.weeks = s.weeksFilter(func(login, _ string) bool { return keep(login) })
+	return result
+}
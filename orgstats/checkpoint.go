@@ -0,0 +1,208 @@
+package orgstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checkpointInterval is how often a checkpoint is saved at most, as saving
+// it gets slower as stats pile up.
+const checkpointInterval = 10 * time.Second
+
+// checkpoint is the progress of a Gather, persisted so an interrupted run
+// can be resumed.
+type checkpoint struct {
+	Sources  []string                     `json:"sources"`
+	Since    time.Time                    `json:"since"`
+	Until    time.Time                    `json:"until"`
+	Period   string                       `json:"period,omitempty"`
+	Settings checkpointSettings           `json:"settings"`
+	Repos    map[string]bool              `json:"repos"`
+	Reviews  map[string]bool              `json:"reviews"`
+	Data     map[string]map[string]Stat   `json:"data"`
+	RepoData map[string]map[string]Stat   `json:"repo_data,omitempty"`
+	Bots     map[string]bool              `json:"bots"`
+	Weeks    map[string]map[string][]Week `json:"weeks,omitempty"`
+	Pulls    map[string][]PullRequest     `json:"pulls,omitempty"`
+	Reviewed map[string][]Review          `json:"reviewed,omitempty"`
+
+	path string
+	// saved is when the checkpoint was last saved, and unsaved the stats
+	// not saved since.
+	saved   time.Time
+	unsaved *Stats
+}
+
+// checkpointSettings are the options changing what is gathered, which a
+// run must share with the checkpoint it resumes.
+type checkpointSettings struct {
+	UserBlacklist       []string `json:"user_blacklist,omitempty"`
+	RepoBlacklist       []string `json:"repo_blacklist,omitempty"`
+	UserAllowlist       []string `json:"user_allowlist,omitempty"`
+	RepoAllowlist       []string `json:"repo_allowlist,omitempty"`
+	ExcludeBots         bool     `json:"exclude_bots,omitempty"`
+	BotPatterns         []string `json:"bot_patterns,omitempty"`
+	ExcludeForks        bool     `json:"exclude_forks,omitempty"`
+	IncludeReviews      bool     `json:"include_reviews,omitempty"`
+	ReviewMembers       bool     `json:"review_members,omitempty"`
+	ReviewTeams         []string `json:"review_teams,omitempty"`
+	OnlyTeams           []string `json:"only_teams,omitempty"`
+	IncludePullRequests bool     `json:"include_pull_requests,omitempty"`
+	ReviewDetails       bool     `json:"review_details,omitempty"`
+	IncludeIssues       bool     `json:"include_issues,omitempty"`
+	Weekly              bool     `json:"weekly,omitempty"`
+	Git                 bool     `json:"git,omitempty"`
+}
+
+func newCheckpointSettings(opts Options) checkpointSettings {
+	return checkpointSettings{
+		UserBlacklist:       opts.UserBlacklist,
+		RepoBlacklist:       opts.RepoBlacklist,
+		UserAllowlist:       opts.UserAllowlist,
+		RepoAllowlist:       opts.RepoAllowlist,
+		ExcludeBots:         opts.ExcludeBots,
+		BotPatterns:         opts.BotPatterns,
+		ExcludeForks:        opts.ExcludeForks,
+		IncludeReviews:      opts.IncludeReviews,
+		ReviewMembers:       opts.ReviewMembers,
+		ReviewTeams:         opts.ReviewTeams,
+		OnlyTeams:           opts.OnlyTeams,
+		IncludePullRequests: opts.IncludePullRequests,
+		ReviewDetails:       opts.ReviewDetails,
+		IncludeIssues:       opts.IncludeIssues,
+		Weekly:              opts.Weekly,
+		Git:                 opts.GitDir != "",
+	}
+}
+
+func newCheckpoint(path string, sources []string, opts Options) *checkpoint {
+	return &checkpoint{
+		Sources:  sources,
+		Since:    opts.Since,
+		Until:    opts.Until,
+		Period:   opts.Period,
+		Settings: newCheckpointSettings(opts),
+		Repos:    map[string]bool{},
+		Reviews:  map[string]bool{},
+		Data:     map[string]map[string]Stat{},
+		path:     path,
+	}
+}
+
+// loadCheckpoint reads the checkpoint saved for the given new one, returning
+// the new one if there is none.
+//
+// A checkpoint of the same period, as given by the user, covers the times
+// it was started with, even if the period is relative and thus now covers
+// other times.
+func loadCheckpoint(want *checkpoint) (*checkpoint, error) {
+	bts, err := os.ReadFile(want.path)
+	if os.IsNotExist(err) {
+		return want, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	cp := &checkpoint{path: want.path}
+	if err := json.Unmarshal(bts, cp); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %s: %w", want.path, err)
+	}
+	samePeriod := want.Period != "" && cp.Period == want.Period
+	if strings.Join(cp.Sources, ",") != strings.Join(want.Sources, ",") ||
+		!samePeriod && (!cp.Since.Equal(want.Since) || !cp.Until.Equal(want.Until)) {
+		return nil, fmt.Errorf(
+			"checkpoint %s is for %v from %v until %v, not %v from %v until %v",
+			want.path, cp.Sources, cp.Since, cp.Until, want.Sources, want.Since, want.Until,
+		)
+	}
+	if !sameSettings(cp.Settings, want.Settings) {
+		return nil, fmt.Errorf("checkpoint %s was gathered with other options, run without --resume to start over", want.path)
+	}
+	if cp.Repos == nil {
+		cp.Repos = map[string]bool{}
+	}
+	if cp.Reviews == nil {
+		cp.Reviews = map[string]bool{}
+	}
+	return cp, nil
+}
+
+// sameSettings compares settings as they are saved, so empty and missing
+// lists are the same.
+func sameSettings(a, b checkpointSettings) bool {
+	ba, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	return bytes.Equal(ba, bb)
+}
+
+// save persists the checkpoint along with the given stats, unless it was
+// saved less than checkpointInterval ago, in which case flush does.
+func (cp *checkpoint) save(stats *Stats) error {
+	if cp.path == "" {
+		return nil
+	}
+	if time.Since(cp.saved) < checkpointInterval {
+		cp.unsaved = stats
+		return nil
+	}
+	return cp.write(stats)
+}
+
+// flush persists the stats not saved yet, if any.
+func (cp *checkpoint) flush() error {
+	if cp.unsaved == nil {
+		return nil
+	}
+	return cp.write(cp.unsaved)
+}
+
+func (cp *checkpoint) write(stats *Stats) error {
+	cp.unsaved = nil
+	cp.saved = time.Now()
+	cp.Data = stats.orgs
+	cp.Bots = stats.bots
+	cp.RepoData = stats.repos
+	cp.Pulls = stats.pulls
+	cp.Reviewed = stats.reviews
+	cp.Weeks = nil
+	for login, repos := range stats.weeks {
+		if cp.Weeks == nil {
+			cp.Weeks = map[string]map[string][]Week{}
+		}
+		cp.Weeks[login] = map[string][]Week{}
+		for repo, weeks := range repos {
+			cp.Weeks[login][repo] = sortedWeeks(weeks)
+		}
+	}
+	bts, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, bts, 0o644); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, cp.path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the checkpoint, once it is no longer needed.
+func (cp *checkpoint) remove() error {
+	if cp.path == "" {
+		return nil
+	}
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
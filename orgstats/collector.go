@@ -0,0 +1,698 @@
+package orgstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// Collector gathers the stats of the given organizations, user accounts
+// and repositories.
+type Collector struct {
+	client *github.Client
+	orgs   []string
+	opts   Options
+
+	userBlacklist, repoBlacklist []Pattern
+	userAllowlist, repoAllowlist []Pattern
+	botPatterns                  []Pattern
+	// onlyMembers are the members of the OnlyTeams of each organization,
+	// once gathered.
+	onlyMembers map[string]map[string]bool
+	err         error
+}
+
+// NewCollector creates a Collector for the given organization, plus the
+// ones given with WithOrgs, if any. The organization may be empty if other
+// sources are given with WithUsers, WithRepos or WithLocal.
+//
+// The client is expected to handle rate limits on its own, for instance
+// by using a githuberrors.Transport. It may be nil if only local
+// repositories are scanned.
+func NewCollector(client *github.Client, org string, opts ...Option) *Collector {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var orgs []string
+	for _, o := range append([]string{org}, options.Orgs...) {
+		if o != "" && !containsFold(orgs, o) {
+			orgs = append(orgs, o)
+		}
+	}
+	c := &Collector{
+		client: client,
+		orgs:   orgs,
+		opts:   options,
+	}
+	for _, l := range []struct {
+		name     string
+		patterns *[]Pattern
+		entries  []string
+	}{
+		{"user blacklist", &c.userBlacklist, options.UserBlacklist},
+		{"repo blacklist", &c.repoBlacklist, options.RepoBlacklist},
+		{"user allowlist", &c.userAllowlist, options.UserAllowlist},
+		{"repo allowlist", &c.repoAllowlist, options.RepoAllowlist},
+		{"bot patterns", &c.botPatterns, options.BotPatterns},
+	} {
+		patterns, err := ParsePatterns(l.entries)
+		if err != nil && c.err == nil {
+			c.err = fmt.Errorf("invalid %s: %w", l.name, err)
+		}
+		*l.patterns = patterns
+	}
+	if client == nil && c.err == nil && (len(orgs)+len(options.Users)+len(options.Repos) > 0 ||
+		options.IncludeReviews || options.IncludePullRequests || options.ReviewDetails || options.IncludeIssues ||
+		options.IncludeTeams || len(options.OnlyTeams) > 0) {
+		c.err = fmt.Errorf("a github client is required to gather anything besides local repositories")
+	}
+	if options.IncludeTeams && len(orgs) == 0 && len(options.OnlyTeams) == 0 && c.err == nil {
+		c.err = fmt.Errorf("teams can only be gathered from organizations")
+	}
+	if options.API != APIREST && options.API != APIGraphQL && c.err == nil {
+		c.err = fmt.Errorf("invalid api: '%s', should be %s or %s", options.API, APIREST, APIGraphQL)
+	}
+	return c
+}
+
+// Org returns the first organization this Collector gathers stats from, or
+// an empty string if there is none.
+//
+// Deprecated: use Orgs or Sources, as a Collector may gather stats from
+// several organizations, user accounts and repositories.
+func (c *Collector) Org() string {
+	if len(c.orgs) == 0 {
+		return ""
+	}
+	return c.orgs[0]
+}
+
+// Orgs returns the organizations this Collector gathers stats from.
+func (c *Collector) Orgs() []string {
+	return c.orgs
+}
+
+// Sources returns all the organizations, user accounts and repositories
+// this Collector gathers stats from.
+func (c *Collector) Sources() []string {
+	var sources []string
+	sources = append(sources, c.orgs...)
+	sources = append(sources, c.opts.Users...)
+	sources = append(sources, c.opts.Repos...)
+	sources = append(sources, c.opts.Local...)
+	return sources
+}
+
+// Options returns the options this Collector was created with.
+func (c *Collector) Options() Options {
+	return c.opts
+}
+
+// Gather a given organization's stats.
+func Gather(ctx context.Context, client *github.Client, org string, opts ...Option) (Stats, error) {
+	return NewCollector(client, org, opts...).Gather(ctx)
+}
+
+// Gather the stats.
+//
+// The stats are broken down by owner: the organization or user the
+// repositories belong to.
+func (c *Collector) Gather(ctx context.Context) (Stats, error) {
+	if c.err != nil {
+		return Stats{}, c.err
+	}
+	cp := newCheckpoint(c.opts.Checkpoint, c.Sources(), c.opts)
+	// the run works on its own copy of the Collector, which may be read
+	// meanwhile, and gathered again afterwards.
+	run := *c
+	if c.opts.Resume && c.opts.Checkpoint != "" {
+		var err error
+		cp, err = loadCheckpoint(cp)
+		if err != nil {
+			return Stats{}, err
+		}
+		// the rest of the run covers the same times the checkpoint does.
+		run.opts.Since, run.opts.Until = cp.Since, cp.Until
+		log.Println("resuming from checkpoint with", len(cp.Repos), "repositories done")
+	}
+	stats, err := run.gather(ctx, cp)
+	if err != nil {
+		// the progress not saved yet is kept for the next run.
+		if ferr := cp.flush(); ferr != nil {
+			log.Println(ferr)
+		}
+		return Stats{}, err
+	}
+	return stats, nil
+}
+
+// gather the stats, along with the ones already in the checkpoint.
+func (c *Collector) gather(ctx context.Context, cp *checkpoint) (Stats, error) {
+	allStats := NewStatsBetween(c.opts.Since, c.opts.Until)
+	allStats.botPatterns = c.botPatterns
+	allStats.excludeBots = c.opts.ExcludeBots
+	allStats.weekly = c.opts.Weekly
+	allStats.identities = c.opts.Identities
+	if c.opts.IncludeTeams || len(c.opts.OnlyTeams) > 0 {
+		if err := c.gatherTeams(ctx, &allStats); err != nil {
+			return Stats{}, err
+		}
+	}
+	for login := range cp.Bots {
+		allStats.bots[login] = true
+	}
+	for org, data := range cp.Data {
+		for login, stat := range data {
+			allStats.record(org, login, stat)
+		}
+	}
+	for repo, data := range cp.RepoData {
+		for login, stat := range data {
+			allStats.recordRepo(repo, login, stat)
+		}
+	}
+	for _, pulls := range cp.Pulls {
+		for _, pr := range pulls {
+			allStats.recordPull(pr)
+		}
+	}
+	for _, reviews := range cp.Reviewed {
+		for _, review := range reviews {
+			allStats.recordReview(review)
+		}
+	}
+	for login, repos := range cp.Weeks {
+		for repo, weeks := range repos {
+			for _, week := range weeks {
+				allStats.recordWeek(login, repo, week.Start, week.Stat)
+			}
+		}
+	}
+	if err := c.gatherLineStats(ctx, cp, &allStats); err != nil {
+		return Stats{}, err
+	}
+
+	log.Println("total authors stats:", len(allStats.data))
+
+	if c.opts.IncludeReviews {
+		for _, org := range c.reviewOrgs(allStats) {
+			users, err := c.reviewers(ctx, org, allStats)
+			if err != nil {
+				return Stats{}, err
+			}
+			var pending []string
+			for _, user := range users {
+				if !cp.Reviews[org+"/"+user] {
+					pending = append(pending, user)
+				}
+			}
+			batch := 1
+			if c.opts.API == APIGraphQL {
+				batch = graphqlBatch
+			}
+			for len(pending) > 0 {
+				if err := ctx.Err(); err != nil {
+					return Stats{}, err
+				}
+				users := pending[:min(batch, len(pending))]
+				pending = pending[len(users):]
+				log.Println("gathering review stats for users:", users, "on org", org)
+				if err := c.gatherReviewStats(ctx, org, users, &allStats); err != nil {
+					return Stats{}, err
+				}
+				for _, user := range users {
+					cp.Reviews[org+"/"+user] = true
+				}
+				if err := cp.save(&allStats); err != nil {
+					return Stats{}, err
+				}
+			}
+		}
+	}
+
+	if err := cp.remove(); err != nil {
+		return Stats{}, err
+	}
+	return allStats, nil
+}
+
+func (c *Collector) gatherReviewStats(ctx context.Context, org string, users []string, allStats *Stats) error {
+	// each login of a user is searched separately, and counted for the
+	// user.
+	var queries, owners []string
+	for _, user := range users {
+		for _, login := range c.opts.Identities.logins(user) {
+			queries = append(queries, fmt.Sprintf("%s is:pr reviewed-by:%s %s", c.reviewScope(org), login, c.createdQualifier()))
+			owners = append(owners, user)
+		}
+	}
+	var reviewed []int
+	if c.opts.API == APIGraphQL {
+		var err error
+		reviewed, err = c.graphqlSearchCounts(ctx, queries)
+		if err != nil {
+			log.Println("failed to gather review stats for users: ", users, "error: ", err)
+			return err
+		}
+	} else {
+		for _, query := range queries {
+			n, err := c.search(ctx, query)
+			if err != nil {
+				log.Println("failed to gather review stats for users: ", users, "error: ", err)
+				return err
+			}
+			reviewed = append(reviewed, n)
+		}
+	}
+	for i, user := range owners {
+		allStats.addReviewStats(org, user, reviewed[i])
+	}
+	return nil
+}
+
+// reviewScope returns the search qualifiers restricting results to the
+// repositories scanned of the given owner: all of them, if the owner is
+// scanned as an organization or user account, or the ones given explicitly
+// otherwise.
+func (c *Collector) reviewScope(org string) string {
+	if containsFold(c.orgs, org) || containsFold(c.opts.Users, org) {
+		return "user:" + org
+	}
+	var qualifiers []string
+	for _, repo := range c.opts.Repos {
+		if ownedBy(repo, org) {
+			qualifiers = append(qualifiers, "repo:"+repo)
+		}
+	}
+	if len(qualifiers) == 0 {
+		return "user:" + org
+	}
+	return strings.Join(qualifiers, " ")
+}
+
+// createdQualifier returns the search qualifier restricting results to the
+// gathering period.
+func (c *Collector) createdQualifier() string {
+	const layout = "2006-01-02"
+	since, until := c.opts.Since, c.opts.Until
+	switch {
+	case !since.IsZero() && !until.IsZero():
+		return fmt.Sprintf("created:%s..%s", since.Format(layout), until.Add(-time.Nanosecond).Format(layout))
+	case !until.IsZero():
+		return fmt.Sprintf("created:<%s", until.Format(layout))
+	default:
+		return fmt.Sprintf("created:>%s", since.Format(layout))
+	}
+}
+
+func (c *Collector) search(ctx context.Context, query string) (int, error) {
+	log.Printf("searching '%s'", query)
+	var result *github.IssuesSearchResult
+	if err := c.do(ctx, "search "+query, func() error {
+		var err error
+		result, _, err = c.client.Search.Issues(ctx, query, &github.SearchOptions{
+			ListOptions: github.ListOptions{
+				PerPage: 1,
+			},
+		})
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("failed to search: %s: %w", query, err)
+	}
+	return result.GetTotal(), nil
+}
+
+// repository is a repository to scan, along with its owner, and its
+// directory, if already on disk.
+type repository struct {
+	*github.Repository
+	org string
+	dir string
+}
+
+func (r repository) fullName() string {
+	return r.org + "/" + r.GetName()
+}
+
+func (c *Collector) gatherLineStats(ctx context.Context, cp *checkpoint, allStats *Stats) error {
+	allRepos, err := c.filteredRepos(ctx)
+	if err != nil {
+		return err
+	}
+	// the checkpoint is only touched by the merge loop below once the
+	// workers start.
+	var pending []repository
+	for _, repo := range allRepos {
+		if cp.Repos[repo.fullName()] {
+			log.Println("ignoring repo already in checkpoint:", repo.fullName())
+			continue
+		}
+		pending = append(pending, repo)
+	}
+
+	concurrency := c.opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan repository)
+	results := make(chan repoStats)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range jobs {
+				var stats []*github.ContributorStats
+				var commits []commit
+				var serr error
+				switch {
+				case repo.dir != "":
+					commits, serr = gitLog(ctx, repo.dir, c.opts.Since, c.opts.Until)
+				case c.opts.GitDir != "":
+					commits, serr = c.getCommits(ctx, repo)
+				default:
+					stats, serr = c.getStats(ctx, repo.org, repo.GetName())
+				}
+				var pulls []pullRequest
+				if serr == nil && (c.opts.IncludePullRequests || c.opts.ReviewDetails) {
+					pulls, serr = c.getPullRequests(ctx, repo)
+				}
+				var issues []activity
+				if serr == nil && c.opts.IncludeIssues {
+					issues, serr = c.getIssueActivity(ctx, repo)
+				}
+				select {
+				case results <- repoStats{repo: repo, stats: stats, commits: commits, pulls: pulls, issues: issues, err: serr}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, repo := range pending {
+			select {
+			case jobs <- repo:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// results are merged by this goroutine only, so Stats needs no locking.
+	defer close(done)
+	for result := range results {
+		if errors.Is(result.err, ErrStatsUnavailable) {
+			log.Println("stats unavailable for repo:", result.repo.fullName())
+			allStats.unavailable = append(allStats.unavailable, result.repo.fullName())
+			continue
+		}
+		if result.err != nil {
+			return result.err
+		}
+		for _, cs := range result.stats {
+			login := Unattributed
+			if cs.Author != nil {
+				login = cs.Author.GetLogin()
+			}
+			if !c.allowedUser(result.repo.org, login) {
+				log.Println("ignoring blacklisted author:", login)
+				continue
+			}
+			log.Println("recording stats for author", login, "on repo", result.repo.fullName())
+			allStats.add(result.repo.org, result.repo.fullName(), cs)
+		}
+		for _, commit := range result.commits {
+			login := c.opts.Identities.commitLogin(commit)
+			if !c.allowedUser(result.repo.org, login) {
+				continue
+			}
+			allStats.addCommit(result.repo.org, result.repo.fullName(), login, commit.when, Stat{
+				Additions: commit.additions,
+				Deletions: commit.deletions,
+				Commits:   1,
+			})
+		}
+		for _, pull := range result.pulls {
+			if c.opts.ReviewDetails {
+				allStats.addPullReviews(result.repo.org, result.repo.fullName(), pull, func(login string) bool {
+					return c.allowedUser(result.repo.org, login)
+				})
+			}
+			if !c.opts.IncludePullRequests || !c.allowedUser(result.repo.org, pull.GetUser().GetLogin()) {
+				continue
+			}
+			allStats.addPull(result.repo.org, result.repo.fullName(), pull.PullRequest)
+		}
+		for _, issue := range result.issues {
+			if !c.allowedUser(result.repo.org, issue.user.GetLogin()) {
+				continue
+			}
+			allStats.addActivity(result.repo.org, result.repo.fullName(), issue)
+		}
+		cp.Repos[result.repo.fullName()] = true
+		if err := cp.save(allStats); err != nil {
+			return err
+		}
+	}
+	sort.Strings(allStats.unavailable)
+	return ctx.Err()
+}
+
+type repoStats struct {
+	repo    repository
+	stats   []*github.ContributorStats
+	commits []commit
+	pulls   []pullRequest
+	issues  []activity
+	err     error
+}
+
+// allowedUser reports whether the user is in the allowlist, if any, and not
+// in the blacklist, nor restricted to the members of some teams of the
+// given owner. Aliases are matched by their canonical login.
+func (c *Collector) allowedUser(org, login string) bool {
+	login = c.opts.Identities.resolve(login)
+	if c.onlyMembers != nil && !c.onlyMembers[strings.ToLower(org)][strings.ToLower(login)] {
+		return false
+	}
+	return allowed(c.userAllowlist, c.userBlacklist, login)
+}
+
+// allowedRepo reports whether the repository is in the allowlist, if any,
+// and not in the blacklist.
+func (c *Collector) allowedRepo(repo repository) bool {
+	return allowed(c.repoAllowlist, c.repoBlacklist, repo.GetName(), repo.fullName())
+}
+
+func allowed(allowlist, blacklist []Pattern, names ...string) bool {
+	if len(allowlist) > 0 && !matchAny(allowlist, names...) {
+		return false
+	}
+	return !matchAny(blacklist, names...)
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, b := range ss {
+		if strings.EqualFold(s, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// Repositories returns the owner/name of the repositories that would be
+// scanned, after applying all filters, without gathering any stats.
+func (c *Collector) Repositories(ctx context.Context) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	repos, err := c.filteredRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.fullName())
+	}
+	return names, nil
+}
+
+// filteredRepos lists the repositories of all sources that should be
+// scanned.
+func (c *Collector) filteredRepos(ctx context.Context) ([]repository, error) {
+	allRepos, err := c.allRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var repos []repository
+	for _, repo := range allRepos {
+		if c.opts.ExcludeForks && repo.GetFork() {
+			log.Println("ignoring forked repo:", repo.fullName())
+			continue
+		}
+		if !c.allowedRepo(repo) {
+			log.Println("ignoring blacklisted repo:", repo.fullName())
+			continue
+		}
+		if ok, reason := c.opts.Filter.match(repo); !ok {
+			log.Println("ignoring filtered repo:", repo.fullName(), reason)
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// allRepos lists the repositories of all sources, without duplicates.
+func (c *Collector) allRepos(ctx context.Context) ([]repository, error) {
+	var allRepos []repository
+	seen := map[string]bool{}
+	add := func(owner string, repos ...*github.Repository) {
+		for _, repo := range repos {
+			r := repository{Repository: repo, org: owner}
+			if seen[strings.ToLower(r.fullName())] {
+				continue
+			}
+			seen[strings.ToLower(r.fullName())] = true
+			allRepos = append(allRepos, r)
+		}
+	}
+
+	for _, org := range c.orgs {
+		repos, err := c.repos(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		add(org, repos...)
+	}
+	for _, user := range c.opts.Users {
+		repos, err := c.userRepos(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		add(user, repos...)
+	}
+	for _, fullName := range c.opts.Repos {
+		owner, name, ok := strings.Cut(fullName, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid repository: '%s', should be owner/name", fullName)
+		}
+		repo, err := c.repo(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		add(owner, repo)
+	}
+	for _, dir := range c.opts.Local {
+		repos, err := localRepos(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			if !seen[strings.ToLower(repo.fullName())] {
+				seen[strings.ToLower(repo.fullName())] = true
+				allRepos = append(allRepos, repo)
+			}
+		}
+	}
+	return allRepos, nil
+}
+
+func (c *Collector) repos(ctx context.Context, org string) ([]*github.Repository, error) {
+	if c.opts.API == APIGraphQL {
+		return c.graphqlRepos(ctx, org, false)
+	}
+	opt := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{PerPage: 10},
+	}
+	var allRepos []*github.Repository
+	for {
+		var repos []*github.Repository
+		var resp *github.Response
+		if err := c.do(ctx, "list repositories", func() error {
+			var err error
+			repos, resp, err = c.client.Repositories.ListByOrg(ctx, org, opt)
+			return err
+		}); err != nil {
+			return allRepos, err
+		}
+		allRepos = append(allRepos, repos...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	log.Println("got", len(allRepos), "repositories from", org)
+	return allRepos, nil
+}
+
+func (c *Collector) userRepos(ctx context.Context, user string) ([]*github.Repository, error) {
+	if c.opts.API == APIGraphQL {
+		return c.graphqlRepos(ctx, user, true)
+	}
+	opt := &github.RepositoryListOptions{
+		Type:        "owner",
+		ListOptions: github.ListOptions{PerPage: 10},
+	}
+	var allRepos []*github.Repository
+	for {
+		var repos []*github.Repository
+		var resp *github.Response
+		if err := c.do(ctx, "list repositories", func() error {
+			var err error
+			repos, resp, err = c.client.Repositories.List(ctx, user, opt)
+			return err
+		}); err != nil {
+			return allRepos, err
+		}
+		allRepos = append(allRepos, repos...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	log.Println("got", len(allRepos), "repositories from", user)
+	return allRepos, nil
+}
+
+func (c *Collector) repo(ctx context.Context, owner, name string) (*github.Repository, error) {
+	var repo *github.Repository
+	err := c.do(ctx, owner+"/"+name, func() error {
+		var err error
+		repo, _, err = c.client.Repositories.Get(ctx, owner, name)
+		return err
+	})
+	return repo, err
+}
+
+func (c *Collector) getStats(ctx context.Context, org, repo string) ([]*github.ContributorStats, error) {
+	var stats []*github.ContributorStats
+	err := c.do(ctx, org+"/"+repo, func() error {
+		var err error
+		stats, _, err = c.client.Repositories.ListContributorsStats(ctx, org, repo)
+		return err
+	})
+	return stats, err
+}
@@ -0,0 +1,16 @@
+// Package orgstats gathers contributor statistics from all repositories of
+// a GitHub organization.
+//
+// The stable API is the Collector, configured with Option values, and the
+// Stats it returns:
+//
+//	stats, err := orgstats.NewCollector(client, "my-org",
+//		orgstats.WithSince(time.Now().AddDate(0, -1, 0)),
+//		orgstats.WithReviews(true),
+//	).Gather(ctx)
+//
+// Stats can then be ranked with Sort and one of the Extract functions.
+//
+// Rate limits are not handled by this package: build the client with a
+// githuberrors.Transport so requests wait for them transparently.
+package orgstats
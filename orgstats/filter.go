@@ -0,0 +1,97 @@
+package orgstats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// Archived repositories handling in a RepoFilter.
+const (
+	ArchivedInclude = ""
+	ArchivedExclude = "exclude"
+	ArchivedOnly    = "only"
+)
+
+// RepoFilter decides which repositories are scanned. Empty fields don't
+// filter anything.
+type RepoFilter struct {
+	// Include scans only the repositories matching any of these patterns,
+	// against either their name or their owner/name.
+	Include []Pattern
+	// Exclude ignores the repositories matching any of these patterns,
+	// against either their name or their owner/name.
+	Exclude []Pattern
+	// Topics scans only the repositories with any of these topics.
+	Topics []string
+	// ExcludeTopics ignores the repositories with any of these topics.
+	ExcludeTopics []string
+	// Languages scans only the repositories with any of these primary
+	// languages.
+	Languages []string
+	// Visibilities scans only the repositories with any of these
+	// visibilities: public, private or internal.
+	Visibilities []string
+	// Archived is one of ArchivedInclude, ArchivedExclude or ArchivedOnly.
+	Archived string
+	// PushedSince ignores the repositories not pushed to since this time.
+	PushedSince time.Time
+}
+
+// match reports whether the repository passes the filter, and if not, why.
+func (f RepoFilter) match(repo repository) (bool, string) {
+	names := []string{repo.GetName(), repo.fullName()}
+	if len(f.Include) > 0 && !matchAny(f.Include, names...) {
+		return false, "not included"
+	}
+	if matchAny(f.Exclude, names...) {
+		return false, "excluded"
+	}
+	if len(f.Topics) > 0 && !anyFold(f.Topics, repo.Topics...) {
+		return false, "topic not included"
+	}
+	if anyFold(f.ExcludeTopics, repo.Topics...) {
+		return false, "topic excluded"
+	}
+	if len(f.Languages) > 0 && !anyFold(f.Languages, repo.GetLanguage()) {
+		return false, fmt.Sprintf("language %q not included", repo.GetLanguage())
+	}
+	if len(f.Visibilities) > 0 && !anyFold(f.Visibilities, visibility(repo.Repository)) {
+		return false, fmt.Sprintf("visibility %q not included", visibility(repo.Repository))
+	}
+	switch {
+	case f.Archived == ArchivedExclude && repo.GetArchived():
+		return false, "archived"
+	case f.Archived == ArchivedOnly && !repo.GetArchived():
+		return false, "not archived"
+	}
+	if !f.PushedSince.IsZero() && repo.GetPushedAt().Before(f.PushedSince) {
+		return false, "not pushed recently"
+	}
+	return true, ""
+}
+
+// visibility returns the repository visibility, falling back to the
+// private flag if the API did not tell.
+func visibility(repo *github.Repository) string {
+	if v := repo.GetVisibility(); v != "" {
+		return strings.ToLower(v)
+	}
+	if repo.GetPrivate() {
+		return "private"
+	}
+	return "public"
+}
+
+// anyFold reports whether any of the values is in the list, case
+// insensitively.
+func anyFold(list []string, values ...string) bool {
+	for _, v := range values {
+		if containsFold(list, v) {
+			return true
+		}
+	}
+	return false
+}
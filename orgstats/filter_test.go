@@ -0,0 +1,78 @@
+package orgstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+	"github.com/matryer/is"
+)
+
+func TestPattern(t *testing.T) {
+	for pattern, tests := range map[string]map[string]bool{
+		"foo":          {"foo": true, "FOO": true, "foobar": false},
+		"svc-*":        {"svc-a": true, "SVC-b": true, "svc": false, "my-svc-a": false},
+		"*-archive":    {"x-archive": true, "archive": false},
+		"foo/*":        {"foo/bar": true, "bar/foo": false},
+		"a[bot]":       {"a[bot]": true, "A[BOT]": true, "ab": true, "a": false},
+		"re:^svc-\\d+": {"svc-1": true, "svc-12x": true, "svc-x": false},
+		"re:bot$":      {"some-BOT": true, "bots": false},
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			is := is.New(t)
+			p, err := ParsePattern(pattern)
+			is.NoErr(err)
+			for name, match := range tests {
+				is.Equal(p.Match(name), match) // name
+			}
+		})
+	}
+
+	for _, pattern := range []string{"re:(", "foo["} {
+		t.Run(pattern, func(t *testing.T) {
+			_, err := ParsePattern(pattern)
+			is.New(t).True(err != nil)
+		})
+	}
+}
+
+func TestRepoFilter(t *testing.T) {
+	mustParse := func(ss ...string) []Pattern {
+		p, err := ParsePatterns(ss)
+		is.New(t).NoErr(err)
+		return p
+	}
+	repo := func(r github.Repository) repository {
+		return repository{Repository: &r, org: "org"}
+	}
+	recent := &github.Timestamp{Time: time.Now()}
+	old := &github.Timestamp{Time: time.Now().AddDate(-1, 0, 0)}
+
+	for name, tt := range map[string]struct {
+		filter RepoFilter
+		repo   repository
+		match  bool
+	}{
+		"empty":            {RepoFilter{}, repo(github.Repository{Name: github.String("a")}), true},
+		"included":         {RepoFilter{Include: mustParse("svc-*")}, repo(github.Repository{Name: github.String("svc-a")}), true},
+		"not included":     {RepoFilter{Include: mustParse("svc-*")}, repo(github.Repository{Name: github.String("a")}), false},
+		"included by full": {RepoFilter{Include: mustParse("org/*")}, repo(github.Repository{Name: github.String("a")}), true},
+		"excluded":         {RepoFilter{Exclude: mustParse("*-archive")}, repo(github.Repository{Name: github.String("a-archive")}), false},
+		"topic":            {RepoFilter{Topics: []string{"go"}}, repo(github.Repository{Topics: []string{"cli", "go"}}), true},
+		"no topic":         {RepoFilter{Topics: []string{"go"}}, repo(github.Repository{}), false},
+		"excluded topic":   {RepoFilter{ExcludeTopics: []string{"deprecated"}}, repo(github.Repository{Topics: []string{"deprecated"}}), false},
+		"language":         {RepoFilter{Languages: []string{"go"}}, repo(github.Repository{Language: github.String("Go")}), true},
+		"other language":   {RepoFilter{Languages: []string{"go"}}, repo(github.Repository{Language: github.String("Rust")}), false},
+		"private":          {RepoFilter{Visibilities: []string{"private"}}, repo(github.Repository{Private: github.Bool(true)}), true},
+		"internal":         {RepoFilter{Visibilities: []string{"private"}}, repo(github.Repository{Visibility: github.String("internal")}), false},
+		"archived":         {RepoFilter{Archived: ArchivedExclude}, repo(github.Repository{Archived: github.Bool(true)}), false},
+		"only archived":    {RepoFilter{Archived: ArchivedOnly}, repo(github.Repository{}), false},
+		"pushed recently":  {RepoFilter{PushedSince: time.Now().AddDate(0, -1, 0)}, repo(github.Repository{PushedAt: recent}), true},
+		"pushed long ago":  {RepoFilter{PushedSince: time.Now().AddDate(0, -1, 0)}, repo(github.Repository{PushedAt: old}), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			match, _ := tt.filter.match(tt.repo)
+			is.New(t).Equal(match, tt.match)
+		})
+	}
+}
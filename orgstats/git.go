@@ -0,0 +1,242 @@
+package orgstats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// commit is a commit read from a git repository.
+type commit struct {
+	name, email          string
+	when                 time.Time
+	additions, deletions int
+}
+
+// login returns the GitHub login of the commit author, if the email is a
+// GitHub noreply one, or the email otherwise.
+func (c commit) login() string {
+	if m := noreplyEmail.FindStringSubmatch(c.email); m != nil {
+		return m[1]
+	}
+	return strings.ToLower(c.email)
+}
+
+// noreplyEmail matches GitHub noreply emails, as in
+// 12345+login@users.noreply.github.com.
+var noreplyEmail = regexp.MustCompile(`(?i)^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// weekStart returns the start of the week, on Sunday, of the given time,
+// as GitHub does.
+func weekStart(t time.Time) time.Time {
+	day := t.UTC().Truncate(24 * time.Hour)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// addCommit records a commit made at the given time, if within the period.
+func (s *Stats) addCommit(org, repo, login string, when time.Time, stat Stat) {
+	if !s.covers(when.UTC()) {
+		return
+	}
+	if isBot(&github.Contributor{Login: &login}, s.botPatterns) {
+		if s.excludeBots {
+			return
+		}
+		s.bots[login] = true
+	}
+	if s.weekly {
+		s.recordWeek(login, repo, weekStart(when), stat)
+	}
+	s.record(org, login, stat)
+	s.recordRepo(repo, login, stat)
+}
+
+// git runs git with the given arguments, returning its output.
+func git(ctx context.Context, args ...string) ([]byte, error) {
+	return gitWithEnv(ctx, nil, args...)
+}
+
+// gitWithEnv is like git, with extra environment variables, which, unlike
+// arguments, other users can't see.
+func gitWithEnv(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	cmd, stderr := gitCommand(ctx, env, args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", gitSubcommand(args), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// gitScan runs git with the given arguments, passing its output to scan as
+// it is written, instead of holding it all in memory.
+func gitScan(ctx context.Context, scan func(r io.Reader) error, args ...string) error {
+	cmd, stderr := gitCommand(ctx, nil, args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git %s: %w", gitSubcommand(args), err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git %s: %w", gitSubcommand(args), err)
+	}
+	if err := scan(out); err != nil {
+		// git is stopped, instead of being left blocked on a full pipe.
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", gitSubcommand(args), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// gitCommand returns the command running git with the given extra
+// environment and arguments, along with its standard error.
+func gitCommand(ctx context.Context, env []string, args ...string) (*exec.Cmd, *bytes.Buffer) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	return cmd, &stderr
+}
+
+// gitSubcommand returns the subcommand of the given git arguments, skipping
+// the global options before it.
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-C" || args[i] == "-c":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// gitAuthEnv returns the environment authenticating git with the given
+// token, as an http.extraHeader set through GIT_CONFIG_* variables, after
+// the ones already in the environment, if any.
+func gitAuthEnv(token string) []string {
+	if token == "" {
+		return nil
+	}
+	n, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	basic := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.extraHeader", n),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: Basic %s", n, basic),
+	}
+}
+
+// mirror bare clones the repository into the git directory, or fetches it
+// if already cloned, returning the path of the clone.
+func (c *Collector) mirror(ctx context.Context, repo repository) (string, error) {
+	url := repo.GetCloneURL()
+	if url == "" {
+		return "", fmt.Errorf("failed to clone %s: no clone url", repo.fullName())
+	}
+	auth := gitAuthEnv(c.opts.GitToken)
+
+	dir := filepath.Join(c.opts.GitDir, repo.org, repo.GetName()+".git")
+	if _, err := os.Stat(dir); err == nil {
+		if _, err := gitWithEnv(ctx, auth, "-C", dir, "fetch", "--prune", "--quiet", url, "+refs/heads/*:refs/heads/*"); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", repo.fullName(), err)
+		}
+		return dir, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repo.fullName(), err)
+	}
+	// cloned to a temporary directory first, so an interrupted clone is
+	// not mistaken for a complete one.
+	tmp := dir + ".tmp"
+	_ = os.RemoveAll(tmp)
+	if _, err := gitWithEnv(ctx, auth, "clone", "--bare", "--quiet", url, tmp); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repo.fullName(), err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repo.fullName(), err)
+	}
+	return dir, nil
+}
+
+// gitLog reads the non-merge commits of the default branch of the git
+// repository in the given directory, with the lines they added and removed.
+// Author names and emails are mapped with the repository .mailmap, if any.
+//
+// Commits are only read within the given times, if not zero. git filters
+// them by commit date, so they still have to be filtered by author date.
+func gitLog(ctx context.Context, dir string, since, until time.Time) ([]commit, error) {
+	if _, err := git(ctx, "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// empty repository
+		return nil, nil
+	}
+	args := []string{"-C", dir, "log", "HEAD", "--no-merges", "--numstat", "--format=%x00%aN%x1f%aE%x1f%aI"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		args = append(args, "--until="+until.Format(time.RFC3339))
+	}
+
+	var commits []commit
+	if err := gitScan(ctx, func(r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if header, ok := strings.CutPrefix(line, "\x00"); ok {
+				fields := strings.Split(header, "\x1f")
+				if len(fields) != 3 {
+					return fmt.Errorf("invalid commit: %q", header)
+				}
+				when, err := time.Parse(time.RFC3339, fields[2])
+				if err != nil {
+					return err
+				}
+				commits = append(commits, commit{name: fields[0], email: fields[1], when: when})
+				continue
+			}
+			if line == "" || len(commits) == 0 {
+				continue
+			}
+			// added, removed and path, separated by tabs, with '-' for
+			// binary files.
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0])
+			removed, _ := strconv.Atoi(fields[1])
+			commits[len(commits)-1].additions += added
+			commits[len(commits)-1].deletions += removed
+		}
+		return scanner.Err()
+	}, args...); err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", dir, err)
+	}
+	return commits, nil
+}
+
+// getCommits clones, or updates, the repository and reads its commits.
+func (c *Collector) getCommits(ctx context.Context, repo repository) ([]commit, error) {
+	dir, err := c.mirror(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return gitLog(ctx, dir, c.opts.Since, c.opts.Until)
+}
@@ -0,0 +1,323 @@
+package orgstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// The APIs stats can be gathered with.
+const (
+	// APIREST uses the REST API only.
+	APIREST = "rest"
+	// APIGraphQL lists repositories, pull requests along with their reviews,
+	// and searches reviews with the GraphQL API, batching what the REST API
+	// needs many requests for. Line stats are only available on the REST
+	// API, so they are still gathered with it, as are issues. The
+	// contributions collection is not used, as it has no line stats either,
+	// and only covers a year at a time.
+	APIGraphQL = "graphql"
+)
+
+// graphqlBatch is how many searches are sent in a single GraphQL request.
+const graphqlBatch = 20
+
+type graphqlError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// graphql runs the query, decoding its data into result.
+func (c *Collector) graphql(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	// GitHub Enterprise serves the REST API on /api/v3/ and the GraphQL one
+	// on /api/graphql.
+	endpoint := "graphql"
+	if strings.HasSuffix(c.client.BaseURL.Path, "/v3/") {
+		endpoint = "../graphql"
+	}
+	return c.do(ctx, "graphql", func() error {
+		req, err := c.client.NewRequest("POST", endpoint, map[string]interface{}{
+			"query":     query,
+			"variables": variables,
+		})
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []graphqlError  `json:"errors"`
+		}
+		if _, err := c.client.Do(ctx, req, &resp); err != nil {
+			return err
+		}
+		if len(resp.Errors) > 0 {
+			return fmt.Errorf("graphql: %s: %s", resp.Errors[0].Type, resp.Errors[0].Message)
+		}
+		return json.Unmarshal(resp.Data, result)
+	})
+}
+
+const graphqlReposQuery = `query($login: String!, $cursor: String) {
+  %s(login: $login) {
+    repositories(first: 100, after: $cursor%s) {
+      nodes {
+        name
+        url
+        isFork
+        isArchived
+        visibility
+        hasIssuesEnabled
+        pushedAt
+        primaryLanguage { name }
+        repositoryTopics(first: 100) { nodes { topic { name } } }
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+type graphqlRepository struct {
+	Name             string    `json:"name"`
+	URL              string    `json:"url"`
+	IsFork           bool      `json:"isFork"`
+	IsArchived       bool      `json:"isArchived"`
+	Visibility       string    `json:"visibility"`
+	HasIssuesEnabled bool      `json:"hasIssuesEnabled"`
+	PushedAt         time.Time `json:"pushedAt"`
+	PrimaryLanguage  *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+}
+
+// repository converts the GraphQL repository to its REST counterpart, with
+// the fields RepoFilter and cloning need.
+func (r graphqlRepository) repository() *github.Repository {
+	repo := &github.Repository{
+		Name:       github.String(r.Name),
+		Fork:       github.Bool(r.IsFork),
+		Archived:   github.Bool(r.IsArchived),
+		Visibility: github.String(strings.ToLower(r.Visibility)),
+		Private:    github.Bool(!strings.EqualFold(r.Visibility, "public")),
+		HasIssues:  github.Bool(r.HasIssuesEnabled),
+		PushedAt:   &github.Timestamp{Time: r.PushedAt},
+	}
+	if r.URL != "" {
+		repo.CloneURL = github.String(r.URL + ".git")
+	}
+	if r.PrimaryLanguage != nil {
+		repo.Language = github.String(r.PrimaryLanguage.Name)
+	}
+	for _, node := range r.RepositoryTopics.Nodes {
+		repo.Topics = append(repo.Topics, node.Topic.Name)
+	}
+	return repo
+}
+
+// graphqlRepos lists the repositories of the given organization, or user
+// account, 100 at a time.
+func (c *Collector) graphqlRepos(ctx context.Context, login string, user bool) ([]*github.Repository, error) {
+	owner, args := "organization", ""
+	if user {
+		owner, args = "user", ", ownerAffiliations: [OWNER]"
+	}
+	query := fmt.Sprintf(graphqlReposQuery, owner, args)
+
+	var allRepos []*github.Repository
+	var cursor *string
+	for {
+		var result map[string]struct {
+			Repositories struct {
+				Nodes    []graphqlRepository `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"repositories"`
+		}
+		if err := c.graphql(ctx, query, map[string]interface{}{
+			"login":  login,
+			"cursor": cursor,
+		}, &result); err != nil {
+			return allRepos, fmt.Errorf("failed to list repositories: %s: %w", login, err)
+		}
+		repos := result[owner].Repositories
+		for _, node := range repos.Nodes {
+			allRepos = append(allRepos, node.repository())
+		}
+		if !repos.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &repos.PageInfo.EndCursor
+	}
+
+	log.Println("got", len(allRepos), "repositories from", login)
+	return allRepos, nil
+}
+
+// graphqlSearchCounts returns the number of issues matching each of the
+// queries, all searched in a single request.
+func (c *Collector) graphqlSearchCounts(ctx context.Context, queries []string) ([]int, error) {
+	var b strings.Builder
+	b.WriteString("query {\n")
+	for i, query := range queries {
+		q, _ := json.Marshal(query)
+		fmt.Fprintf(&b, "  s%d: search(query: %s, type: ISSUE, first: 0) { issueCount }\n", i, q)
+	}
+	b.WriteString("}")
+
+	var result map[string]struct {
+		IssueCount int `json:"issueCount"`
+	}
+	if err := c.graphql(ctx, b.String(), nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	counts := make([]int, len(queries))
+	for i := range queries {
+		counts[i] = result[fmt.Sprintf("s%d", i)].IssueCount
+	}
+	return counts, nil
+}
+
+const graphqlPullsQuery = `query($owner: String!, $name: String!, $cursor: String, $reviews: Boolean!) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: 50, after: $cursor, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes {
+        number
+        createdAt
+        mergedAt
+        closedAt
+        additions
+        deletions
+        author { login __typename }
+        reviews(first: 100) @include(if: $reviews) {
+          nodes {
+            state
+            submittedAt
+            author { login __typename }
+            comments { totalCount }
+          }
+        }
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+type graphqlActor struct {
+	Login    string `json:"login"`
+	Typename string `json:"__typename"`
+}
+
+// user converts the GraphQL actor to its REST counterpart, where the logins
+// of GitHub Apps end in '[bot]'.
+func (a *graphqlActor) user() *github.User {
+	if a == nil {
+		return nil
+	}
+	if a.Typename == "Bot" {
+		return &github.User{Login: github.String(a.Login + "[bot]"), Type: github.String("Bot")}
+	}
+	return &github.User{Login: github.String(a.Login), Type: github.String(a.Typename)}
+}
+
+type graphqlPullRequest struct {
+	Number    int           `json:"number"`
+	CreatedAt time.Time     `json:"createdAt"`
+	MergedAt  *time.Time    `json:"mergedAt"`
+	ClosedAt  *time.Time    `json:"closedAt"`
+	Additions int           `json:"additions"`
+	Deletions int           `json:"deletions"`
+	Author    *graphqlActor `json:"author"`
+	Reviews   struct {
+		Nodes []struct {
+			State       string        `json:"state"`
+			SubmittedAt *time.Time    `json:"submittedAt"`
+			Author      *graphqlActor `json:"author"`
+			Comments    struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"comments"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+}
+
+// pullRequest converts the GraphQL pull request to its REST counterpart,
+// along with its reviews and review comments, of which only the authors
+// are needed.
+func (p graphqlPullRequest) pullRequest() pullRequest {
+	pr := pullRequest{PullRequest: &github.PullRequest{
+		Number:    github.Int(p.Number),
+		User:      p.Author.user(),
+		CreatedAt: &p.CreatedAt,
+		MergedAt:  p.MergedAt,
+		ClosedAt:  p.ClosedAt,
+		Additions: github.Int(p.Additions),
+		Deletions: github.Int(p.Deletions),
+	}}
+	for _, node := range p.Reviews.Nodes {
+		user := node.Author.user()
+		pr.reviews = append(pr.reviews, &github.PullRequestReview{
+			User:        user,
+			State:       github.String(node.State),
+			SubmittedAt: node.SubmittedAt,
+		})
+		for i := 0; i < node.Comments.TotalCount; i++ {
+			pr.comments = append(pr.comments, &github.PullRequestComment{User: user})
+		}
+	}
+	return pr
+}
+
+// graphqlPullRequests returns the pull requests of the repository created
+// within the gathering period, newest first, 50 at a time, with their sizes
+// and their first 100 reviews, instead of a request for each of them.
+func (c *Collector) graphqlPullRequests(ctx context.Context, repo repository) ([]pullRequest, error) {
+	var result []pullRequest
+	var cursor *string
+	for {
+		var data struct {
+			Repository struct {
+				PullRequests struct {
+					Nodes    []graphqlPullRequest `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}
+		if err := c.graphql(ctx, graphqlPullsQuery, map[string]interface{}{
+			"owner":   repo.org,
+			"name":    repo.GetName(),
+			"cursor":  cursor,
+			"reviews": c.opts.ReviewDetails,
+		}, &data); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %s: %w", repo.fullName(), err)
+		}
+		pulls := data.Repository.PullRequests
+		for _, node := range pulls.Nodes {
+			if !c.opts.Until.IsZero() && !node.CreatedAt.Before(c.opts.Until) {
+				continue
+			}
+			if !c.opts.Since.IsZero() && node.CreatedAt.Before(c.opts.Since) {
+				return result, nil
+			}
+			result = append(result, node.pullRequest())
+		}
+		if !pulls.PageInfo.HasNextPage {
+			return result, nil
+		}
+		cursor = &pulls.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,159 @@
+package orgstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherGraphQL(t *testing.T) {
+	const repos = 25
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	count := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("/orgs/foo/repos", count(func(w http.ResponseWriter, r *http.Request) {
+		var page int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		page = max(page, 1)
+		var names []string
+		for i := (page - 1) * 10; i < min(page*10, repos); i++ {
+			names = append(names, fmt.Sprintf(`{"name":"r%d"}`, i))
+		}
+		if page*10 < repos {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		fmt.Fprint(w, "["+strings.Join(names, ",")+"]")
+	}))
+	for i := 0; i < repos; i++ {
+		login := fmt.Sprintf("u%d", i%5)
+		mux.HandleFunc(fmt.Sprintf("/repos/foo/r%d/stats/contributors", i), count(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `[{"author":{"login":%q},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}]`, login)
+		}))
+	}
+	mux.HandleFunc("/search/issues", count(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count":2}`)
+	}))
+	mux.HandleFunc("/graphql", count(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if strings.Contains(body.Query, "organization(login: $login)") {
+			var nodes []string
+			for i := 0; i < repos; i++ {
+				nodes = append(nodes, fmt.Sprintf(`{"name":"r%d","visibility":"PUBLIC","pushedAt":"2026-01-01T00:00:00Z"}`, i))
+			}
+			fmt.Fprintf(w, `{"data":{"organization":{"repositories":{"nodes":[%s],"pageInfo":{"hasNextPage":false}}}}}`, strings.Join(nodes, ","))
+			return
+		}
+		var counts []string
+		for _, alias := range regexp.MustCompile(`(s\d+): search`).FindAllStringSubmatch(body.Query, -1) {
+			counts = append(counts, fmt.Sprintf(`%q:{"issueCount":2}`, alias[1]))
+		}
+		fmt.Fprintf(w, `{"data":{%s}}`, strings.Join(counts, ","))
+	}))
+
+	is := is.New(t)
+
+	stats, err := Gather(context.Background(), newTestClient(t, mux), "foo", WithReviews(true))
+	is.NoErr(err)
+	is.Equal(stats.For("u0"), Stat{Additions: 5, Deletions: 5, Commits: 5, Reviews: 2})
+	rest := requests.Swap(0)
+	is.Equal(rest, int32(3+repos+5)) // 3 pages of repositories, plus 5 searches
+
+	stats, err = Gather(context.Background(), newTestClient(t, mux), "foo", WithReviews(true), WithAPI(APIGraphQL))
+	is.NoErr(err)
+	is.Equal(stats.For("u0"), Stat{Additions: 5, Deletions: 5, Commits: 5, Reviews: 2})
+	is.Equal(requests.Load(), int32(1+repos+1))
+
+	_, err = Gather(context.Background(), newTestClient(t, mux), "foo", WithAPI("soap"))
+	is.True(err != nil)
+}
+
+func TestGatherGraphQLPullRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("pull requests listed with the REST API")
+	})
+	var pages int
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if strings.Contains(body.Query, "organization(login: $login)") {
+			fmt.Fprint(w, `{"data":{"organization":{"repositories":{"nodes":[{"name":"a"}],"pageInfo":{"hasNextPage":false}}}}}`)
+			return
+		}
+		pages++
+		if body.Variables["cursor"] == nil {
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequests":{"nodes":[
+				{"number":4,"createdAt":"2026-10-05T00:00:00Z","additions":1,"author":{"login":"alice","__typename":"User"}},
+				{"number":3,"createdAt":"2026-09-10T00:00:00Z","mergedAt":"2026-09-10T02:00:00Z","closedAt":"2026-09-10T02:00:00Z","additions":100,"deletions":20,
+				 "author":{"login":"alice","__typename":"User"},
+				 "reviews":{"nodes":[
+					{"state":"CHANGES_REQUESTED","submittedAt":"2026-09-10T01:00:00Z","author":{"login":"carol","__typename":"User"},"comments":{"totalCount":2}},
+					{"state":"APPROVED","submittedAt":"2026-09-10T01:30:00Z","author":{"login":"carol","__typename":"User"},"comments":{"totalCount":0}},
+					{"state":"APPROVED","submittedAt":"2026-09-10T01:00:00Z","author":{"login":"renovate","__typename":"Bot"},"comments":{"totalCount":0}}
+				 ]}}
+			],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequests":{"nodes":[
+			{"number":2,"createdAt":"2026-09-05T00:00:00Z","closedAt":"2026-09-06T00:00:00Z","additions":5,"deletions":5,"author":{"login":"bob","__typename":"User"},"reviews":{"nodes":[]}},
+			{"number":1,"createdAt":"2026-08-01T00:00:00Z","author":{"login":"bob","__typename":"User"}}
+		],"pageInfo":{"hasNextPage":true,"endCursor":"c2"}}}}}`)
+	})
+
+	since, _ := time.Parse("2006-01-02", "2026-09-01")
+	until, _ := time.Parse("2006-01-02", "2026-10-01")
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithSince(since),
+		WithUntil(until),
+		WithPullRequests(true),
+		WithReviewDetails(true),
+		WithAPI(APIGraphQL),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(pages, 2) // stops at the first pull request before the period
+	is.Equal(stats.For("alice"), Stat{
+		PullRequests:          1,
+		MergedPullRequests:    1,
+		MedianTimeToMerge:     2 * time.Hour,
+		MedianPullRequestSize: 120,
+	})
+	is.Equal(stats.For("bob"), Stat{
+		PullRequests:          1,
+		ClosedPullRequests:    1,
+		MedianPullRequestSize: 10,
+	})
+	is.Equal(stats.For("carol"), Stat{
+		Approvals:               1,
+		ChangesRequested:        1,
+		ReviewComments:          2,
+		MedianTimeToFirstReview: time.Hour,
+	})
+	is.True(stats.IsBot("renovate[bot]"))
+}
@@ -0,0 +1,177 @@
+package orgstats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Unattributed is the login commits that can not be attributed to anyone
+// are recorded under.
+const Unattributed = "(unattributed)"
+
+// Identities merges the several logins and emails people commit with into
+// a single, canonical, login.
+//
+// It is read from a file in the .mailmap format, see git-check-mailmap(1),
+// with extra lines of login aliases, as in:
+//
+//	# emails are mapped as in .mailmap
+//	Alice <alice@example.com> <alice@old-job.com>
+//	# the first login is the canonical one, the rest are its aliases,
+//	# either logins or emails
+//	@alice @alice-work alice@example.com
+type Identities struct {
+	mailmap []mailmapEntry
+	aliases map[string]string
+}
+
+type mailmapEntry struct {
+	properName, properEmail string
+	commitName, commitEmail string
+}
+
+// LoadIdentities reads the identities file at the given path.
+func LoadIdentities(path string) (*Identities, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identities: %w", err)
+	}
+	defer f.Close()
+	return ParseIdentities(f)
+}
+
+// ParseIdentities reads identities in the format described in Identities.
+func ParseIdentities(r io.Reader) (*Identities, error) {
+	ids := &Identities{aliases: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			fields := strings.Fields(line)
+			canonical := strings.TrimPrefix(fields[0], "@")
+			if canonical == "" || len(fields) < 2 {
+				return nil, fmt.Errorf("invalid identities: line %d: %q: should be '@login alias...'", n, line)
+			}
+			for _, alias := range fields[1:] {
+				ids.aliases[strings.ToLower(strings.TrimPrefix(alias, "@"))] = canonical
+			}
+			continue
+		}
+		entry, ok := parseMailmapLine(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid identities: line %d: %q: should be in the .mailmap format", n, line)
+		}
+		ids.mailmap = append(ids.mailmap, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read identities: %w", err)
+	}
+	return ids, nil
+}
+
+// parseMailmapLine parses a .mailmap line, with one email, to fix the name
+// of the commits with it, or two, to fix the name and email of the commits
+// with the second one, and optionally the name before it.
+func parseMailmapLine(line string) (mailmapEntry, bool) {
+	var names, emails []string
+	rest := line
+	for len(emails) < 2 {
+		start := strings.Index(rest, "<")
+		end := strings.Index(rest, ">")
+		if start < 0 || end < start {
+			break
+		}
+		names = append(names, strings.TrimSpace(rest[:start]))
+		emails = append(emails, strings.TrimSpace(rest[start+1:end]))
+		rest = rest[end+1:]
+	}
+	switch {
+	case len(emails) == 1 && strings.TrimSpace(rest) == "":
+		return mailmapEntry{properName: names[0], commitEmail: emails[0]}, true
+	case len(emails) == 2 && strings.TrimSpace(rest) == "":
+		return mailmapEntry{
+			properName:  names[0],
+			properEmail: emails[0],
+			commitName:  names[1],
+			commitEmail: emails[1],
+		}, true
+	default:
+		return mailmapEntry{}, false
+	}
+}
+
+// resolve returns the canonical login of the given login or email.
+func (ids *Identities) resolve(login string) string {
+	if ids == nil {
+		return login
+	}
+	if canonical, ok := ids.aliases[strings.ToLower(login)]; ok {
+		return canonical
+	}
+	return login
+}
+
+// logins returns the GitHub logins of the given canonical login: itself
+// and its login aliases.
+func (ids *Identities) logins(canonical string) []string {
+	logins := []string{canonical}
+	if ids == nil {
+		return logins
+	}
+	for alias, login := range ids.aliases {
+		if login == canonical && !strings.Contains(alias, "@") && !strings.EqualFold(alias, canonical) {
+			logins = append(logins, alias)
+		}
+	}
+	sort.Strings(logins[1:])
+	return logins
+}
+
+// mapCommit returns the name and email of the commit author according to
+// the mailmap entries.
+func (ids *Identities) mapCommit(name, email string) (string, string) {
+	if ids == nil {
+		return name, email
+	}
+	var match *mailmapEntry
+	for i, entry := range ids.mailmap {
+		if !strings.EqualFold(entry.commitEmail, email) {
+			continue
+		}
+		if entry.commitName == "" && match == nil {
+			match = &ids.mailmap[i]
+		}
+		if entry.commitName != "" && strings.EqualFold(entry.commitName, name) {
+			match = &ids.mailmap[i]
+			break
+		}
+	}
+	if match == nil {
+		return name, email
+	}
+	if match.properName != "" {
+		name = match.properName
+	}
+	if match.properEmail != "" {
+		email = match.properEmail
+	}
+	return name, email
+}
+
+// commitLogin returns the canonical login of the commit author, or
+// Unattributed if it has no email.
+func (ids *Identities) commitLogin(c commit) string {
+	c.name, c.email = ids.mapCommit(c.name, c.email)
+	if c.email == "" {
+		return Unattributed
+	}
+	return ids.resolve(c.login())
+}
@@ -0,0 +1,115 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseIdentities(t *testing.T) {
+	is := is.New(t)
+
+	ids, err := ParseIdentities(strings.NewReader(`
+# a comment
+Alice <alice@example.com>
+Alice <alice@example.com> <alice@old-job.com>
+<bob@example.com> Bobby <bob@laptop.local> # trailing comment
+@alice @alice-work alice@example.com
+@bob bob@example.com
+`))
+	is.NoErr(err)
+
+	name, email := ids.mapCommit("al", "ALICE@old-job.com")
+	is.Equal(name, "Alice")
+	is.Equal(email, "alice@example.com")
+	name, email = ids.mapCommit("Bobby", "bob@laptop.local")
+	is.Equal(name, "Bobby")
+	is.Equal(email, "bob@example.com")
+	name, email = ids.mapCommit("Robert", "bob@laptop.local")
+	is.Equal(name, "Robert")
+	is.Equal(email, "bob@laptop.local")
+
+	is.Equal(ids.resolve("Alice-Work"), "alice")
+	is.Equal(ids.resolve("alice@example.com"), "alice")
+	is.Equal(ids.resolve("carol"), "carol")
+	is.Equal(ids.logins("alice"), []string{"alice", "alice-work"})
+
+	is.Equal(ids.commitLogin(commit{name: "Bobby", email: "bob@laptop.local"}), "bob")
+	is.Equal(ids.commitLogin(commit{email: "1+carol@users.noreply.github.com"}), "carol")
+	is.Equal(ids.commitLogin(commit{name: "Nobody"}), Unattributed)
+
+	var none *Identities
+	is.Equal(none.resolve("alice-work"), "alice-work")
+	is.Equal(none.commitLogin(commit{email: "Alice@example.com"}), "alice@example.com")
+
+	for _, invalid := range []string{"@alice", "Alice", "Alice <alice@example.com> bob"} {
+		_, err := ParseIdentities(strings.NewReader(invalid))
+		is.True(err != nil) // invalid line
+	}
+}
+
+func TestGatherIdentities(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]},
+			{"author":{"login":"alice-work"},"weeks":[{"w":1,"a":5,"d":1,"c":2}]},
+			{"author":null,"weeks":[{"w":1,"a":7,"d":3,"c":4}]}
+		]`)
+	})
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		reviewer := strings.Fields(strings.TrimPrefix(r.URL.Query().Get("q"), "user:foo is:pr reviewed-by:"))[0]
+		fmt.Fprintf(w, `{"total_count":%d}`, len(reviewer))
+	})
+
+	is := is.New(t)
+
+	ids, err := ParseIdentities(strings.NewReader("@alice @alice-work"))
+	is.NoErr(err)
+	stats, err := Gather(context.Background(), newTestClient(t, mux), "foo", WithIdentities(ids), WithReviews(true))
+	is.NoErr(err)
+	logins := stats.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{Unattributed, "alice"})
+	// reviews searched for both alice and alice-work.
+	is.Equal(stats.For("alice"), Stat{Additions: 15, Deletions: 3, Commits: 3, Reviews: 15})
+	is.Equal(stats.For(Unattributed), Stat{Additions: 7, Deletions: 3, Commits: 4})
+	is.Equal(stats.Humans().Logins(), []string{"alice"})
+
+	stats, err = Gather(context.Background(), newTestClient(t, mux), "foo", WithUserBlacklist("alice"), WithIdentities(ids))
+	is.NoErr(err)
+	is.Equal(stats.Logins(), []string{Unattributed})
+}
+
+func TestGatherLocalIdentities(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "acme")
+	initFixtureRepo(t, filepath.Join(root, "api"),
+		fixtureCommit{"alice@example.com", "2026-09-01T10:00:00Z", "a.txt", 10},
+		fixtureCommit{"alice@old-job.com", "2026-09-02T10:00:00Z", "a.txt", 20},
+		fixtureCommit{"1+alice@users.noreply.github.com", "2026-09-03T10:00:00Z", "a.txt", 30},
+		fixtureCommit{"bob@example.com", "2026-09-04T10:00:00Z", "b.txt", 40},
+	)
+
+	is := is.New(t)
+
+	ids, err := ParseIdentities(strings.NewReader(`
+<alice@example.com> <alice@old-job.com>
+@alice alice@example.com
+`))
+	is.NoErr(err)
+	stats, err := NewCollector(nil, "", WithLocal(root), WithIdentities(ids)).Gather(context.Background())
+	is.NoErr(err)
+	logins := stats.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"alice", "bob@example.com"})
+	is.Equal(stats.For("alice"), Stat{Additions: 60, Commits: 3})
+}
@@ -0,0 +1,147 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// activity is something a user did within the gathering period.
+type activity struct {
+	user *github.User
+	stat Stat
+}
+
+// addActivity records the given activity, unless the user is a bot and
+// bots are excluded.
+func (s *Stats) addActivity(org, repo string, a activity) {
+	if a.user == nil {
+		return
+	}
+	login := s.identities.resolve(a.user.GetLogin())
+	if isBot(&github.Contributor{Login: a.user.Login, Type: a.user.Type}, s.botPatterns) {
+		if s.excludeBots {
+			return
+		}
+		s.bots[login] = true
+	}
+	s.record(org, login, a.stat)
+	s.recordRepo(repo, login, a.stat)
+}
+
+// getIssueActivity returns the issues opened, closed and commented on in
+// the repository within the gathering period. Pull requests, which GitHub
+// also treats as issues, are ignored.
+func (c *Collector) getIssueActivity(ctx context.Context, repo repository) ([]activity, error) {
+	if repo.HasIssues != nil && !repo.GetHasIssues() {
+		return nil, nil
+	}
+	var result []activity
+	in := func(t time.Time) bool {
+		return (c.opts.Since.IsZero() || !t.Before(c.opts.Since)) &&
+			(c.opts.Until.IsZero() || t.Before(c.opts.Until))
+	}
+
+	// issues updated since the start of the period include every issue
+	// opened, closed or commented on within it.
+	pulls := map[int]bool{}
+	opts := &github.IssueListByRepoOptions{
+		State: "all",
+		Since: c.opts.Since,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		var issues []*github.Issue
+		var resp *github.Response
+		if err := c.do(ctx, "issues "+repo.fullName(), func() error {
+			var err error
+			issues, resp, err = c.client.Issues.ListByRepo(ctx, repo.org, repo.GetName(), opts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list issues: %s: %w", repo.fullName(), err)
+		}
+		for _, issue := range issues {
+			if issue.IsPullRequest() {
+				pulls[issue.GetNumber()] = true
+				continue
+			}
+			if in(issue.GetCreatedAt()) {
+				result = append(result, activity{user: issue.GetUser(), stat: Stat{IssuesOpened: 1}})
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	eventOpts := &github.ListOptions{PerPage: 100}
+events:
+	for {
+		var events []*github.IssueEvent
+		var resp *github.Response
+		if err := c.do(ctx, "issue events "+repo.fullName(), func() error {
+			var err error
+			events, resp, err = c.client.Issues.ListRepositoryEvents(ctx, repo.org, repo.GetName(), eventOpts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list issue events: %s: %w", repo.fullName(), err)
+		}
+		for _, event := range events {
+			// events are listed newest first.
+			if !c.opts.Since.IsZero() && event.GetCreatedAt().Before(c.opts.Since) {
+				break events
+			}
+			if event.GetEvent() != "closed" || event.GetIssue().IsPullRequest() || !in(event.GetCreatedAt()) {
+				continue
+			}
+			result = append(result, activity{user: event.GetActor(), stat: Stat{IssuesClosed: 1}})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		eventOpts.Page = resp.NextPage
+	}
+
+	commentOpts := &github.IssueListCommentsOptions{
+		Sort:      github.String("created"),
+		Direction: github.String("desc"),
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	if !c.opts.Since.IsZero() {
+		commentOpts.Since = &c.opts.Since
+	}
+	for {
+		var comments []*github.IssueComment
+		var resp *github.Response
+		if err := c.do(ctx, "issue comments "+repo.fullName(), func() error {
+			var err error
+			comments, resp, err = c.client.Issues.ListComments(ctx, repo.org, repo.GetName(), 0, commentOpts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list issue comments: %s: %w", repo.fullName(), err)
+		}
+		for _, comment := range comments {
+			if !c.opts.Since.IsZero() && comment.GetCreatedAt().Before(c.opts.Since) {
+				return result, nil
+			}
+			number, _ := strconv.Atoi(path.Base(comment.GetIssueURL()))
+			if pulls[number] || !in(comment.GetCreatedAt()) {
+				continue
+			}
+			result = append(result, activity{user: comment.GetUser(), stat: Stat{IssueComments: 1}})
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		commentOpts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,64 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a","has_issues":true},{"name":"b","has_issues":false}]`)
+	})
+	for _, repo := range []string{"a", "b"} {
+		mux.HandleFunc("/repos/foo/"+repo+"/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[]`)
+		})
+	}
+	mux.HandleFunc("/repos/foo/a/issues", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"number":3,"user":{"login":"alice"},"created_at":"2026-09-10T00:00:00Z"},
+			{"number":2,"user":{"login":"bob"},"created_at":"2026-09-05T00:00:00Z","pull_request":{"url":"x"}},
+			{"number":1,"user":{"login":"carol"},"created_at":"2026-08-01T00:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("/repos/foo/a/issues/events", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"event":"closed","actor":{"login":"bob"},"created_at":"2026-09-11T00:00:00Z","issue":{"number":3}},
+			{"event":"closed","actor":{"login":"bob"},"created_at":"2026-09-06T00:00:00Z","issue":{"number":2,"pull_request":{"url":"x"}}},
+			{"event":"labeled","actor":{"login":"bob"},"created_at":"2026-09-05T00:00:00Z","issue":{"number":3}},
+			{"event":"closed","actor":{"login":"bob"},"created_at":"2026-08-02T00:00:00Z","issue":{"number":1}}
+		]`)
+	})
+	mux.HandleFunc("/repos/foo/a/issues/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"user":{"login":"bob"},"created_at":"2026-09-10T01:00:00Z","issue_url":"https://api.github.com/repos/foo/a/issues/3"},
+			{"user":{"login":"triage[bot]"},"created_at":"2026-09-10T00:10:00Z","issue_url":"https://api.github.com/repos/foo/a/issues/3"},
+			{"user":{"login":"alice"},"created_at":"2026-09-05T01:00:00Z","issue_url":"https://api.github.com/repos/foo/a/issues/2"},
+			{"user":{"login":"carol"},"created_at":"2026-08-01T01:00:00Z","issue_url":"https://api.github.com/repos/foo/a/issues/1"}
+		]`)
+	})
+
+	since, _ := time.Parse("2006-01-02", "2026-09-01")
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithSince(since),
+		WithIssues(true),
+		WithExcludeBots(true),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.For("alice"), Stat{IssuesOpened: 1})
+	is.Equal(stats.For("bob"), Stat{IssuesClosed: 1, IssueComments: 1})
+	is.Equal(stats.For("carol"), Stat{})
+	is.Equal(stats.For("triage[bot]"), Stat{})
+	is.Equal(Sort(stats, ExtractTriage)[0], StatPair{Key: "bob", Value: 2})
+}
@@ -0,0 +1,67 @@
+package orgstats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// isGitRepo reports whether the directory is the root of a git repository,
+// either a checkout or a bare one.
+func isGitRepo(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// localRepo returns the repository in the given directory, owned by the
+// given owner.
+func localRepo(owner, dir string) repository {
+	name := strings.TrimSuffix(filepath.Base(dir), ".git")
+	return repository{
+		Repository: &github.Repository{Name: github.String(name)},
+		org:        owner,
+		dir:        dir,
+	}
+}
+
+// localRepos returns the repositories in the given directory: either the
+// directory itself, owned by its parent directory, or the repositories
+// right inside it, owned by the directory.
+func localRepos(dir string) ([]repository, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory: %s: %w", dir, err)
+	}
+	if isGitRepo(abs) {
+		return []repository{localRepo(filepath.Base(filepath.Dir(abs)), abs)}, nil
+	}
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory: %w", err)
+	}
+	var repos []repository
+	for _, entry := range entries {
+		path := filepath.Join(abs, entry.Name())
+		if entry.IsDir() && isGitRepo(path) {
+			repos = append(repos, localRepo(filepath.Base(abs), path))
+		}
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no git repositories found in %s", dir)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].GetName() < repos[j].GetName()
+	})
+	return repos, nil
+}
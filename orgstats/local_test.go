@@ -0,0 +1,49 @@
+package orgstats
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherLocal(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "acme")
+	initFixtureRepo(t, filepath.Join(root, "api"),
+		fixtureCommit{"alice@example.com", "2026-09-01T10:00:00Z", "a.txt", 10},
+		fixtureCommit{"bob@example.com", "2026-09-02T10:00:00Z", "b.txt", 20},
+	)
+	initFixtureRepo(t, filepath.Join(root, "web"),
+		fixtureCommit{"alice@example.com", "2026-09-03T10:00:00Z", "a.txt", 30},
+	)
+	if err := os.MkdirAll(filepath.Join(root, "not-a-repo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(t.TempDir(), "gitea", "tools")
+	initFixtureRepo(t, other,
+		fixtureCommit{"alice@example.com", "2026-09-04T10:00:00Z", "a.txt", 40},
+	)
+
+	is := is.New(t)
+
+	c := NewCollector(nil, "", WithLocal(root, other), WithRepoBlacklist("web"))
+	repos, err := c.Repositories(context.Background())
+	is.NoErr(err)
+	is.Equal(repos, []string{"acme/api", "gitea/tools"})
+
+	stats, err := c.Gather(context.Background())
+	is.NoErr(err)
+	is.Equal(stats.Orgs(), []string{"acme", "gitea"})
+	is.Equal(stats.For("alice@example.com"), Stat{Additions: 50, Commits: 2})
+	logins := stats.ForOrg("acme").Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"alice@example.com", "bob@example.com"})
+
+	_, err = NewCollector(nil, "", WithLocal(filepath.Join(root, "not-a-repo"))).Gather(context.Background())
+	is.True(err != nil)
+	_, err = NewCollector(nil, "foo").Gather(context.Background())
+	is.True(err != nil)
+}
@@ -0,0 +1,131 @@
+package orgstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// reviewOrgs returns the owners to gather review stats on: the ones with
+// stats, and the organizations scanned, as their members might have
+// reviewed pull requests without committing.
+func (c *Collector) reviewOrgs(stats Stats) []string {
+	orgs := stats.Orgs()
+	for _, org := range c.orgs {
+		if !containsFold(orgs, org) {
+			orgs = append(orgs, org)
+		}
+	}
+	sort.Strings(orgs)
+	return orgs
+}
+
+// reviewers returns the users to gather review stats for on the given
+// owner, sorted: the ones that committed, plus the organization members and
+// the members of the review teams, depending on the options.
+func (c *Collector) reviewers(ctx context.Context, org string, stats Stats) ([]string, error) {
+	var users []string
+	for _, login := range stats.ForOrg(org).Humans().Logins() {
+		// authors read from git are keyed by their email unless mapped to a
+		// login, and emails can't be searched for.
+		if strings.Contains(login, "@") {
+			log.Println("ignoring reviews of author without a login:", login)
+			continue
+		}
+		users = append(users, login)
+	}
+	add := func(members []*github.User) {
+		for _, member := range members {
+			login := c.opts.Identities.resolve(member.GetLogin())
+			if containsFold(users, login) || !c.allowedUser(org, login) || stats.IsBot(login) ||
+				isBot(&github.Contributor{Login: member.Login, Type: member.Type}, c.botPatterns) {
+				continue
+			}
+			users = append(users, login)
+		}
+	}
+
+	if c.opts.ReviewMembers && containsFold(c.orgs, org) {
+		members, err := c.members(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		add(members)
+	}
+	for _, team := range c.opts.ReviewTeams {
+		owner, slug, explicit := strings.Cut(team, "/")
+		if !explicit {
+			owner, slug = org, team
+		}
+		if !strings.EqualFold(owner, org) || !containsFold(c.orgs, org) {
+			continue
+		}
+		members, err := c.teamMembers(ctx, org, slug)
+		var gerr *github.ErrorResponse
+		if !explicit && errors.As(err, &gerr) && gerr.Response.StatusCode == http.StatusNotFound {
+			log.Println("team", slug, "not found on org", org)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		add(members)
+	}
+
+	sort.Strings(users)
+	return users, nil
+}
+
+// members returns the members of the organization.
+func (c *Collector) members(ctx context.Context, org string) ([]*github.User, error) {
+	opts := &github.ListMembersOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var result []*github.User
+	for {
+		var members []*github.User
+		var resp *github.Response
+		if err := c.do(ctx, "members "+org, func() error {
+			var err error
+			members, resp, err = c.client.Organizations.ListMembers(ctx, org, opts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list members: %s: %w", org, err)
+		}
+		result = append(result, members...)
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// teamMembers returns the members of the organization's team.
+func (c *Collector) teamMembers(ctx context.Context, org, slug string) ([]*github.User, error) {
+	opts := &github.TeamListTeamMembersOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var result []*github.User
+	for {
+		var members []*github.User
+		var resp *github.Response
+		if err := c.do(ctx, "team "+org+"/"+slug, func() error {
+			var err error
+			members, resp, err = c.client.Teams.ListTeamMembersBySlug(ctx, org, slug, opts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list team members: %s/%s: %w", org, slug, err)
+		}
+		result = append(result, members...)
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
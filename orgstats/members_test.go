@@ -0,0 +1,59 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherReviewers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+	})
+	mux.HandleFunc("/orgs/foo/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"},{"login":"dave"},{"login":"ci","type":"Bot"}]`)
+	})
+	mux.HandleFunc("/orgs/foo/teams/security/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"login":"carol"}]`)
+	})
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		reviewer := strings.Fields(strings.TrimPrefix(r.URL.Query().Get("q"), "user:foo is:pr reviewed-by:"))[0]
+		if reviewer == "dave" {
+			fmt.Fprint(w, `{"total_count":0}`)
+			return
+		}
+		fmt.Fprintf(w, `{"total_count":%d}`, len(reviewer))
+	})
+
+	is := is.New(t)
+
+	stats, err := Gather(context.Background(), newTestClient(t, mux), "foo", WithReviews(true))
+	is.NoErr(err)
+	is.Equal(stats.Logins(), []string{"alice"})
+
+	stats, err = Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithReviews(true),
+		WithReviewMembers(true),
+		WithReviewTeams("security", "missing"),
+	)
+	is.NoErr(err)
+	is.Equal(stats.For("alice"), Stat{Additions: 10, Deletions: 2, Commits: 1, Reviews: 5})
+	is.Equal(stats.For("bob"), Stat{Reviews: 3})
+	is.Equal(stats.For("carol"), Stat{Reviews: 5})
+	is.Equal(stats.For("ci"), Stat{})
+	logins := stats.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"alice", "bob", "carol"}) // dave reviewed nothing
+}
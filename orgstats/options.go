@@ -0,0 +1,352 @@
+package orgstats
+
+import "time"
+
+// Options configures what a Collector gathers.
+//
+// New knobs are added as new fields with a matching Option, so existing
+// callers keep compiling.
+type Options struct {
+	// Orgs are more organizations to gather stats from.
+	Orgs []string
+	// Users are user accounts whose repositories stats are gathered from.
+	Users []string
+	// Repos are repositories, in the owner/name form, to gather stats from.
+	Repos []string
+	// Local are directories of git repositories already on disk to gather
+	// stats from, without any API access. Each one is either a repository,
+	// owned by its parent directory, or contains repositories, owned by it.
+	// Only line stats are available for them.
+	Local []string
+	// UserBlacklist contains the logins to ignore. Each entry is parsed with
+	// ParsePattern, so it can also be a glob or a regular expression.
+	UserBlacklist []string
+	// RepoBlacklist contains the repository names to ignore. Each entry is
+	// parsed with ParsePattern, and matched against both the repository name
+	// and its owner/name.
+	RepoBlacklist []string
+	// UserAllowlist, if not empty, contains the only logins to consider,
+	// in the same format as UserBlacklist.
+	UserAllowlist []string
+	// RepoAllowlist, if not empty, contains the only repositories to scan,
+	// in the same format as RepoBlacklist.
+	RepoAllowlist []string
+	// ExcludeBots ignores automation accounts, instead of just telling them
+	// apart with Stats.IsBot.
+	ExcludeBots bool
+	// BotPatterns are more logins to consider bots, besides GitHub Apps and
+	// logins ending in '[bot]', in the format accepted by ParsePattern.
+	BotPatterns []string
+	// Since ignores activity older than the given time, if not zero.
+	Since time.Time
+	// Until ignores activity from the given time on, if not zero.
+	Until time.Time
+	// IncludeReviews also gathers the number of pull requests reviewed by
+	// each contributor.
+	IncludeReviews bool
+	// ReviewMembers, along with IncludeReviews, also gathers the number of
+	// pull requests reviewed by the members of the organizations, even if
+	// they did not commit.
+	ReviewMembers bool
+	// ReviewTeams, along with IncludeReviews, also gathers the number of
+	// pull requests reviewed by the members of the given teams, even if they
+	// did not commit. Each team is either an 'org/slug', or a slug looked up
+	// on every organization.
+	ReviewTeams []string
+	// IncludeTeams also gathers the teams of each organization, and their
+	// members, for Stats.ByTeam.
+	IncludeTeams bool
+	// OnlyTeams restricts the stats of each organization to the members of
+	// its given teams, and Stats.ByTeam to these teams. Each team is either
+	// an 'org/slug', or a slug looked up on every organization.
+	OnlyTeams []string
+	// IncludePullRequests also gathers the pull requests opened by each
+	// contributor within the period, and how many were merged or closed.
+	IncludePullRequests bool
+	// ReviewDetails also gathers the approvals, change requests and review
+	// comments given by each reviewer on the pull requests opened within
+	// the period, and how long they took to review them.
+	ReviewDetails bool
+	// IncludeIssues also gathers the issues opened, closed and commented on
+	// by each user within the period.
+	IncludeIssues bool
+	// Weekly keeps the activity of each week, per user and repository, as
+	// returned by Stats.WeeksFor and Stats.WeeksForRepo.
+	Weekly bool
+	// ExcludeForks ignores forked repositories.
+	ExcludeForks bool
+	// Filter decides which repositories are scanned.
+	Filter RepoFilter
+	// GitDir, if not empty, is where repositories are bare cloned to, and
+	// line stats computed from, instead of asking GitHub for them. This is
+	// exact to the second, rather than summarized by week, and is not
+	// limited to the last 10k commits.
+	GitDir string
+	// GitToken authenticates git when cloning into GitDir, if not empty.
+	GitToken string
+	// Identities, if not nil, merges the logins and emails of each person
+	// into a canonical login before aggregating.
+	Identities *Identities
+	// API is the GitHub API used to gather stats, either APIREST or
+	// APIGraphQL.
+	API string
+	// Concurrency is how many repositories are scanned in parallel.
+	Concurrency int
+	// RetryPolicy controls how data GitHub is still computing is waited
+	// for.
+	RetryPolicy RetryPolicy
+	// Checkpoint is the file progress is saved to while gathering, if not
+	// empty. It is removed once gathering finishes.
+	Checkpoint string
+	// Resume skips the work already done according to the Checkpoint, which
+	// must have been gathered with the same options.
+	Resume bool
+	// Period is how Since and Until were given, as in 'since 30d'. When
+	// resuming, a checkpoint of the same Period is used even if its times
+	// differ, as relative periods cover other times on each run.
+	Period string
+}
+
+// Option changes a Collector's Options.
+type Option func(*Options)
+
+// WithOrgs gathers stats from the given organizations too.
+func WithOrgs(orgs ...string) Option {
+	return func(o *Options) {
+		o.Orgs = append(o.Orgs, orgs...)
+	}
+}
+
+// WithUsers gathers stats from the repositories of the given user accounts.
+func WithUsers(logins ...string) Option {
+	return func(o *Options) {
+		o.Users = append(o.Users, logins...)
+	}
+}
+
+// WithRepos gathers stats from the given repositories, in the owner/name
+// form.
+func WithRepos(repos ...string) Option {
+	return func(o *Options) {
+		o.Repos = append(o.Repos, repos...)
+	}
+}
+
+// WithLocal gathers stats from the git repositories in the given
+// directories.
+func WithLocal(dirs ...string) Option {
+	return func(o *Options) {
+		o.Local = append(o.Local, dirs...)
+	}
+}
+
+// WithUserBlacklist ignores the given users.
+func WithUserBlacklist(logins ...string) Option {
+	return func(o *Options) {
+		o.UserBlacklist = append(o.UserBlacklist, logins...)
+	}
+}
+
+// WithRepoBlacklist ignores the given repositories.
+func WithRepoBlacklist(repos ...string) Option {
+	return func(o *Options) {
+		o.RepoBlacklist = append(o.RepoBlacklist, repos...)
+	}
+}
+
+// WithUserAllowlist only considers the given users.
+func WithUserAllowlist(logins ...string) Option {
+	return func(o *Options) {
+		o.UserAllowlist = append(o.UserAllowlist, logins...)
+	}
+}
+
+// WithRepoAllowlist only scans the given repositories.
+func WithRepoAllowlist(repos ...string) Option {
+	return func(o *Options) {
+		o.RepoAllowlist = append(o.RepoAllowlist, repos...)
+	}
+}
+
+// WithExcludeBots enables or disables ignoring automation accounts.
+func WithExcludeBots(exclude bool) Option {
+	return func(o *Options) {
+		o.ExcludeBots = exclude
+	}
+}
+
+// WithBotPatterns considers the users matching the given patterns bots.
+func WithBotPatterns(patterns ...string) Option {
+	return func(o *Options) {
+		o.BotPatterns = append(o.BotPatterns, patterns...)
+	}
+}
+
+// WithSince ignores activity older than the given time.
+func WithSince(t time.Time) Option {
+	return func(o *Options) {
+		o.Since = t
+	}
+}
+
+// WithUntil ignores activity from the given time on.
+func WithUntil(t time.Time) Option {
+	return func(o *Options) {
+		o.Until = t
+	}
+}
+
+// WithReviews enables or disables gathering review stats.
+func WithReviews(include bool) Option {
+	return func(o *Options) {
+		o.IncludeReviews = include
+	}
+}
+
+// WithReviewMembers enables or disables gathering review stats for all
+// organization members. It needs WithReviews.
+func WithReviewMembers(include bool) Option {
+	return func(o *Options) {
+		o.ReviewMembers = include
+	}
+}
+
+// WithReviewTeams gathers review stats for the members of the given teams.
+// It needs WithReviews.
+func WithReviewTeams(teams ...string) Option {
+	return func(o *Options) {
+		o.ReviewTeams = append(o.ReviewTeams, teams...)
+	}
+}
+
+// WithTeams enables or disables gathering the teams of each organization.
+func WithTeams(include bool) Option {
+	return func(o *Options) {
+		o.IncludeTeams = include
+	}
+}
+
+// WithOnlyTeams restricts the stats of each organization to the members of
+// its given teams.
+func WithOnlyTeams(teams ...string) Option {
+	return func(o *Options) {
+		o.OnlyTeams = append(o.OnlyTeams, teams...)
+	}
+}
+
+// WithPullRequests enables or disables gathering pull request stats.
+func WithPullRequests(include bool) Option {
+	return func(o *Options) {
+		o.IncludePullRequests = include
+	}
+}
+
+// WithReviewDetails enables or disables gathering detailed review stats.
+func WithReviewDetails(include bool) Option {
+	return func(o *Options) {
+		o.ReviewDetails = include
+	}
+}
+
+// WithIssues enables or disables gathering issue stats.
+func WithIssues(include bool) Option {
+	return func(o *Options) {
+		o.IncludeIssues = include
+	}
+}
+
+// WithWeekly enables or disables keeping the weekly activity.
+func WithWeekly(weekly bool) Option {
+	return func(o *Options) {
+		o.Weekly = weekly
+	}
+}
+
+// WithExcludeForks enables or disables ignoring forked repositories.
+func WithExcludeForks(exclude bool) Option {
+	return func(o *Options) {
+		o.ExcludeForks = exclude
+	}
+}
+
+// WithRepoFilter sets which repositories are scanned.
+func WithRepoFilter(f RepoFilter) Option {
+	return func(o *Options) {
+		o.Filter = f
+	}
+}
+
+// WithGitDir computes line stats from bare clones of the repositories in
+// the given directory.
+func WithGitDir(dir string) Option {
+	return func(o *Options) {
+		o.GitDir = dir
+	}
+}
+
+// WithGitToken authenticates git with the given token when cloning.
+func WithGitToken(token string) Option {
+	return func(o *Options) {
+		o.GitToken = token
+	}
+}
+
+// WithIdentities merges the logins and emails of each person according to
+// the given identities.
+func WithIdentities(ids *Identities) Option {
+	return func(o *Options) {
+		o.Identities = ids
+	}
+}
+
+// WithAPI sets the GitHub API used to gather stats, either APIREST or
+// APIGraphQL.
+func WithAPI(api string) Option {
+	return func(o *Options) {
+		o.API = api
+	}
+}
+
+// WithConcurrency sets how many repositories are scanned in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+// WithRetryPolicy sets how data GitHub is still computing is waited for.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = p
+	}
+}
+
+// WithCheckpoint saves the progress to the given file while gathering.
+func WithCheckpoint(path string) Option {
+	return func(o *Options) {
+		o.Checkpoint = path
+	}
+}
+
+// WithPeriod sets how Since and Until were given, so a checkpoint of a
+// relative period can be resumed.
+func WithPeriod(period string) Option {
+	return func(o *Options) {
+		o.Period = period
+	}
+}
+
+// WithResume enables or disables resuming from the checkpoint file.
+func WithResume(resume bool) Option {
+	return func(o *Options) {
+		o.Resume = resume
+	}
+}
+
+func defaultOptions() Options {
+	return Options{
+		API:         APIREST,
+		Concurrency: 1,
+		RetryPolicy: DefaultRetryPolicy,
+	}
+}
@@ -0,0 +1,75 @@
+package orgstats
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Pattern matches names, case insensitively, either exactly, by glob, as in
+// 'svc-*', or by regular expression when prefixed with 're:', as in
+// 're:^svc-[0-9]+$'.
+type Pattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// ParsePattern parses and validates the given pattern.
+func ParsePattern(s string) (Pattern, error) {
+	if expr, ok := strings.CutPrefix(s, "re:"); ok {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("invalid regular expression '%s': %w", expr, err)
+		}
+		return Pattern{raw: s, re: re}, nil
+	}
+	if _, err := path.Match(s, ""); err != nil {
+		return Pattern{}, fmt.Errorf("invalid glob '%s': %w", s, err)
+	}
+	return Pattern{raw: s}, nil
+}
+
+// ParsePatterns parses and validates all the given patterns.
+func ParsePatterns(ss []string) ([]Pattern, error) {
+	patterns := make([]Pattern, 0, len(ss))
+	for _, s := range ss {
+		p, err := ParsePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Match reports whether the given name matches the pattern.
+func (p Pattern) Match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	// exact matches first, so names like 'dependabot[bot]' aren't taken as
+	// globs only.
+	if strings.EqualFold(p.raw, name) {
+		return true
+	}
+	ok, _ := path.Match(strings.ToLower(p.raw), strings.ToLower(name))
+	return ok
+}
+
+// String returns the pattern as it was given.
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// matchAny reports whether any of the patterns match any of the names.
+func matchAny(patterns []Pattern, names ...string) bool {
+	for _, p := range patterns {
+		for _, name := range names {
+			if p.Match(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
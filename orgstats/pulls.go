@@ -0,0 +1,230 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// PullRequest is a pull request opened within the gathering period.
+type PullRequest struct {
+	Org       string    `json:"org"`
+	Repo      string    `json:"repo"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	MergedAt  time.Time `json:"merged_at"`
+	ClosedAt  time.Time `json:"closed_at"`
+	Size      int       `json:"size"`
+}
+
+// stat returns the pull request counts of the pull request.
+func (pr PullRequest) stat() Stat {
+	stat := Stat{PullRequests: 1}
+	switch {
+	case !pr.MergedAt.IsZero():
+		stat.MergedPullRequests = 1
+	case !pr.ClosedAt.IsZero():
+		stat.ClosedPullRequests = 1
+	}
+	return stat
+}
+
+// PullRequestsFor returns the pull requests opened by the given user.
+func (s Stats) PullRequestsFor(login string) []PullRequest {
+	return s.pulls[login]
+}
+
+// recordPull keeps the pull request for its author's medians.
+func (s *Stats) recordPull(pr PullRequest) {
+	if s.pulls == nil {
+		s.pulls = make(map[string][]PullRequest)
+	}
+	s.pulls[pr.Author] = append(s.pulls[pr.Author], pr)
+}
+
+func (s *Stats) addPull(org, repo string, pull *github.PullRequest) {
+	if pull.GetUser() == nil || !s.covers(pull.GetCreatedAt().UTC()) {
+		return
+	}
+	author := &github.Contributor{
+		Login: pull.GetUser().Login,
+		Type:  pull.GetUser().Type,
+	}
+	if isBot(author, s.botPatterns) {
+		if s.excludeBots {
+			return
+		}
+		s.bots[s.identities.resolve(author.GetLogin())] = true
+	}
+	pr := PullRequest{
+		Org:       org,
+		Repo:      repo,
+		Author:    s.identities.resolve(author.GetLogin()),
+		CreatedAt: pull.GetCreatedAt().UTC(),
+		MergedAt:  pull.GetMergedAt().UTC(),
+		ClosedAt:  pull.GetClosedAt().UTC(),
+		Size:      pull.GetAdditions() + pull.GetDeletions(),
+	}
+	s.recordPull(pr)
+	s.record(org, pr.Author, pr.stat())
+	s.recordRepo(repo, pr.Author, pr.stat())
+}
+
+// withMedians returns the stat with the medians of the given pull requests.
+func withMedians(stat Stat, pulls []PullRequest) Stat {
+	var sizes []int
+	var toMerge []time.Duration
+	for _, pr := range pulls {
+		sizes = append(sizes, pr.Size)
+		if !pr.MergedAt.IsZero() {
+			toMerge = append(toMerge, pr.MergedAt.Sub(pr.CreatedAt))
+		}
+	}
+	stat.MedianPullRequestSize = median(sizes)
+	stat.MedianTimeToMerge = median(toMerge)
+	return stat
+}
+
+func median[T int | time.Duration](values []T) T {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]T{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// pullsFilter returns the pull requests keep returns true for.
+func (s Stats) pullsFilter(keep func(pr PullRequest) bool) map[string][]PullRequest {
+	if s.pulls == nil {
+		return nil
+	}
+	result := make(map[string][]PullRequest)
+	for login, pulls := range s.pulls {
+		for _, pr := range pulls {
+			if keep(pr) {
+				result[login] = append(result[login], pr)
+			}
+		}
+	}
+	return result
+}
+
+// pullRequest is a pull request along with its reviews and review
+// comments, if gathered.
+type pullRequest struct {
+	*github.PullRequest
+	reviews  []*github.PullRequestReview
+	comments []*github.PullRequestComment
+}
+
+// getPullRequests returns the pull requests of the repository created
+// within the gathering period, newest first, with their sizes or reviews,
+// depending on the options.
+func (c *Collector) getPullRequests(ctx context.Context, repo repository) ([]pullRequest, error) {
+	if c.opts.API == APIGraphQL {
+		return c.graphqlPullRequests(ctx, repo)
+	}
+	opts := &github.PullRequestListOptions{
+		State:     "all",
+		Sort:      "created",
+		Direction: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var result []pullRequest
+	for {
+		var pulls []*github.PullRequest
+		var resp *github.Response
+		if err := c.do(ctx, "pulls "+repo.fullName(), func() error {
+			var err error
+			pulls, resp, err = c.client.PullRequests.List(ctx, repo.org, repo.GetName(), opts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %s: %w", repo.fullName(), err)
+		}
+		for _, pull := range pulls {
+			created := pull.GetCreatedAt()
+			if !c.opts.Until.IsZero() && !created.Before(c.opts.Until) {
+				continue
+			}
+			if !c.opts.Since.IsZero() && created.Before(c.opts.Since) {
+				return result, nil
+			}
+			pr, err := c.getPullRequest(ctx, repo, pull)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, pr)
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// getPullRequest gets the details of a listed pull request the options ask
+// for.
+func (c *Collector) getPullRequest(ctx context.Context, repo repository, pull *github.PullRequest) (pullRequest, error) {
+	pr := pullRequest{PullRequest: pull}
+	number := pull.GetNumber()
+	if c.opts.IncludePullRequests {
+		// the listing does not include the size, only the pull request
+		// itself does.
+		if err := c.do(ctx, "pull "+repo.fullName(), func() error {
+			var err error
+			pr.PullRequest, _, err = c.client.PullRequests.Get(ctx, repo.org, repo.GetName(), number)
+			return err
+		}); err != nil {
+			return pr, fmt.Errorf("failed to get pull request: %s#%d: %w", repo.fullName(), number, err)
+		}
+	}
+	if !c.opts.ReviewDetails {
+		return pr, nil
+	}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		var reviews []*github.PullRequestReview
+		var resp *github.Response
+		if err := c.do(ctx, "reviews "+repo.fullName(), func() error {
+			var err error
+			reviews, resp, err = c.client.PullRequests.ListReviews(ctx, repo.org, repo.GetName(), number, opts)
+			return err
+		}); err != nil {
+			return pr, fmt.Errorf("failed to list reviews: %s#%d: %w", repo.fullName(), number, err)
+		}
+		pr.reviews = append(pr.reviews, reviews...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	commentOpts := &github.PullRequestListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		var comments []*github.PullRequestComment
+		var resp *github.Response
+		if err := c.do(ctx, "review comments "+repo.fullName(), func() error {
+			var err error
+			comments, resp, err = c.client.PullRequests.ListComments(ctx, repo.org, repo.GetName(), number, commentOpts)
+			return err
+		}); err != nil {
+			return pr, fmt.Errorf("failed to list review comments: %s#%d: %w", repo.fullName(), number, err)
+		}
+		pr.comments = append(pr.comments, comments...)
+		if resp.NextPage == 0 {
+			return pr, nil
+		}
+		commentOpts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,122 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherPullRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	pulls := []string{
+		`{"number":5,"user":{"login":"alice"},"created_at":"2026-10-05T00:00:00Z","additions":10,"deletions":0}`,
+		`{"number":4,"user":{"login":"alice"},"created_at":"2026-09-10T00:00:00Z","merged_at":"2026-09-10T02:00:00Z","closed_at":"2026-09-10T02:00:00Z","additions":100,"deletions":20}`,
+		`{"number":3,"user":{"login":"alice"},"created_at":"2026-09-05T00:00:00Z","merged_at":"2026-09-05T06:00:00Z","closed_at":"2026-09-05T06:00:00Z","additions":10,"deletions":10}`,
+		`{"number":2,"user":{"login":"bob"},"created_at":"2026-09-02T00:00:00Z","closed_at":"2026-09-03T00:00:00Z","additions":1,"deletions":1}`,
+		`{"number":1,"user":{"login":"alice"},"created_at":"2026-08-01T00:00:00Z","additions":1,"deletions":1}`,
+	}
+	mux.HandleFunc("/repos/foo/a/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[")
+		for i, pull := range pulls {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprint(w, pull)
+		}
+		fmt.Fprint(w, "]")
+	})
+	for i, pull := range pulls {
+		pull := pull
+		mux.HandleFunc(fmt.Sprintf("/repos/foo/a/pulls/%d", len(pulls)-i), func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, pull)
+		})
+	}
+
+	since, _ := time.Parse("2006-01-02", "2026-09-01")
+	until, _ := time.Parse("2006-01-02", "2026-10-01")
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithSince(since),
+		WithUntil(until),
+		WithPullRequests(true),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.For("alice"), Stat{
+		PullRequests:          2,
+		MergedPullRequests:    2,
+		MedianTimeToMerge:     4 * time.Hour,
+		MedianPullRequestSize: 70,
+	})
+	is.Equal(stats.For("bob"), Stat{
+		PullRequests:          1,
+		ClosedPullRequests:    1,
+		MedianPullRequestSize: 2,
+	})
+	is.Equal(Sort(stats, ExtractMergedPullRequests)[0], StatPair{Key: "alice", Value: 2})
+	is.Equal(stats.ForRepo("foo/a").For("alice").MergedPullRequests, 2)
+}
+
+func TestGatherReviewDetails(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"number":2,"user":{"login":"alice"},"created_at":"2026-09-10T00:00:00Z"},
+			{"number":1,"user":{"login":"bob"},"created_at":"2026-09-05T00:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls/2/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"user":{"login":"alice"},"state":"COMMENTED","submitted_at":"2026-09-10T00:30:00Z"},
+			{"user":{"login":"carol"},"state":"CHANGES_REQUESTED","submitted_at":"2026-09-10T01:00:00Z"},
+			{"user":{"login":"carol"},"state":"APPROVED","submitted_at":"2026-09-10T05:00:00Z"},
+			{"user":{"login":"bob"},"state":"PENDING"}
+		]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls/2/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"user":{"login":"carol"}},{"user":{"login":"carol"}},{"user":{"login":"alice"}}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"user":{"login":"carol"},"state":"APPROVED","submitted_at":"2026-09-05T03:00:00Z"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithReviewDetails(true),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.Logins(), []string{"carol"})
+	is.Equal(stats.For("carol"), Stat{
+		Approvals:               2,
+		ChangesRequested:        1,
+		ReviewComments:          2,
+		MedianTimeToFirstReview: 2 * time.Hour,
+	})
+	is.Equal(len(stats.ReviewsFor("carol")), 2)
+}
@@ -0,0 +1,98 @@
+package orgstats
+
+import (
+	"sort"
+	"strings"
+)
+
+// recordRepo adds the given stat to the user in the repository.
+func (s *Stats) recordRepo(repo, login string, stat Stat) {
+	if s.repos == nil {
+		s.repos = make(map[string]map[string]Stat)
+	}
+	if s.repos[repo] == nil {
+		s.repos[repo] = make(map[string]Stat)
+	}
+	s.repos[repo][login] = s.repos[repo][login].plus(stat)
+}
+
+// Repos returns the repositories, in the owner/name form, with any
+// activity, sorted.
+func (s Stats) Repos() []string {
+	repos := make([]string, 0, len(s.repos))
+	for repo := range s.repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// ForRepo returns the stats of the given repository, in the owner/name
+// form, only.
+//
+// Reviews gathered WithReviews are always zero, as they are counted by
+// searching each owner's pull requests, which doesn't tell repositories
+// apart. Approvals, change requests and review comments gathered
+// WithReviewDetails come from each pull request, so they are kept.
+func (s Stats) ForRepo(repo string) Stats {
+	data := map[string]Stat{}
+	for name, logins := range s.repos {
+		if !strings.EqualFold(name, repo) {
+			continue
+		}
+		for login, stat := range logins {
+			data[login] = data[login].plus(stat)
+		}
+	}
+	return Stats{
+		data: data,
+		repos: s.reposFilter(func(name, _ string) bool {
+			return strings.EqualFold(name, repo)
+		}),
+		weeks: s.weeksFilter(func(_, name string) bool {
+			return strings.EqualFold(name, repo)
+		}),
+		pulls: s.pullsFilter(func(pr PullRequest) bool {
+			return strings.EqualFold(pr.Repo, repo)
+		}),
+		reviews: s.reviewsFilter(func(review Review) bool {
+			return strings.EqualFold(review.Repo, repo)
+		}),
+		bots:  s.bots,
+		since: s.since,
+		until: s.until,
+	}
+}
+
+// ReposFor returns the stats of the given user in each repository they
+// contributed to.
+func (s Stats) ReposFor(login string) map[string]Stat {
+	result := map[string]Stat{}
+	for repo, logins := range s.repos {
+		if stat, ok := logins[login]; ok {
+			result[repo] = stat
+		}
+	}
+	return result
+}
+
+// reposFilter returns the repository stats of the repositories and logins
+// keep returns true for.
+func (s Stats) reposFilter(keep func(repo, login string) bool) map[string]map[string]Stat {
+	if s.repos == nil {
+		return nil
+	}
+	result := make(map[string]map[string]Stat)
+	for repo, logins := range s.repos {
+		for login, stat := range logins {
+			if !keep(repo, login) {
+				continue
+			}
+			if result[repo] == nil {
+				result[repo] = make(map[string]Stat)
+			}
+			result[repo][login] = stat
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package orgstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// ErrStatsUnavailable is returned when GitHub did not finish computing the
+// requested data within the RetryPolicy limits.
+var ErrStatsUnavailable = errors.New("stats unavailable")
+
+// RetryPolicy controls how requests answered with 202 Accepted, which
+// GitHub uses while it computes the data in background, are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of requests made.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled on each
+	// following one.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries.
+	MaxBackoff time.Duration
+	// Deadline caps the total time spent retrying a single request.
+	Deadline time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used unless WithRetryPolicy is
+// given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    10,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Deadline:       5 * time.Minute,
+}
+
+// backoff returns how long to wait before the given retry, with full
+// jitter so workers don't retry in lockstep.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff << retry
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// do calls fn until it succeeds, retrying 202 Accepted responses according
+// to the RetryPolicy.
+func (c *Collector) do(ctx context.Context, what string, fn func() error) error {
+	policy := c.opts.RetryPolicy
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if _, ok := err.(*github.AcceptedError); !ok {
+			return err
+		}
+
+		wait := policy.backoff(attempt - 1)
+		if attempt >= policy.MaxAttempts ||
+			(policy.Deadline > 0 && time.Since(start)+wait > policy.Deadline) {
+			return fmt.Errorf("%s: %w after %d attempts", what, ErrStatsUnavailable, attempt)
+		}
+		log.Printf("%s: not ready yet, retrying in %v", what, wait)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep pauses for the given duration, returning early with the context
+// error if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,130 @@
+package orgstats
+
+import (
+	"time"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// Review is the first review of a user on a pull request opened within the
+// gathering period.
+type Review struct {
+	Org      string `json:"org"`
+	Repo     string `json:"repo"`
+	Reviewer string `json:"reviewer"`
+	Number   int    `json:"number"`
+	// Latency is the time between the pull request being opened and the
+	// review.
+	Latency time.Duration `json:"latency"`
+}
+
+// ReviewsFor returns the first reviews of the given user on each pull
+// request.
+func (s Stats) ReviewsFor(login string) []Review {
+	return s.reviews[login]
+}
+
+// recordReview keeps the review for its reviewer's median latency.
+func (s *Stats) recordReview(review Review) {
+	if s.reviews == nil {
+		s.reviews = make(map[string][]Review)
+	}
+	s.reviews[review.Reviewer] = append(s.reviews[review.Reviewer], review)
+}
+
+// addPullReviews records the approvals, change requests and review
+// comments on the pull request, by reviewer. Pull request authors are not
+// reviewers of their own pull requests.
+func (s *Stats) addPullReviews(org, repo string, pull pullRequest, allowed func(login string) bool) {
+	if !s.covers(pull.GetCreatedAt().UTC()) {
+		return
+	}
+	author := s.identities.resolve(pull.GetUser().GetLogin())
+	reviewer := func(user *github.User) (string, bool) {
+		if user == nil {
+			return "", false
+		}
+		login := s.identities.resolve(user.GetLogin())
+		if login == author || !allowed(login) {
+			return "", false
+		}
+		if isBot(&github.Contributor{Login: user.Login, Type: user.Type}, s.botPatterns) {
+			if s.excludeBots {
+				return "", false
+			}
+			s.bots[login] = true
+		}
+		return login, true
+	}
+
+	stats := map[string]Stat{}
+	first := map[string]time.Time{}
+	for _, review := range pull.reviews {
+		login, ok := reviewer(review.GetUser())
+		if !ok || review.GetState() == "PENDING" {
+			continue
+		}
+		stat := stats[login]
+		switch review.GetState() {
+		case "APPROVED":
+			stat.Approvals++
+		case "CHANGES_REQUESTED":
+			stat.ChangesRequested++
+		}
+		stats[login] = stat
+		submitted := review.GetSubmittedAt()
+		if t, ok := first[login]; !ok || submitted.Before(t) {
+			first[login] = submitted
+		}
+	}
+	for _, comment := range pull.comments {
+		login, ok := reviewer(comment.GetUser())
+		if !ok {
+			continue
+		}
+		stat := stats[login]
+		stat.ReviewComments++
+		stats[login] = stat
+	}
+
+	for login, stat := range stats {
+		s.record(org, login, stat)
+		s.recordRepo(repo, login, stat)
+		if t, ok := first[login]; ok {
+			s.recordReview(Review{
+				Org:      org,
+				Repo:     repo,
+				Reviewer: login,
+				Number:   pull.GetNumber(),
+				Latency:  t.Sub(pull.GetCreatedAt()),
+			})
+		}
+	}
+}
+
+// withReviewMedians returns the stat with the median latency of the given
+// reviews.
+func withReviewMedians(stat Stat, reviews []Review) Stat {
+	var latencies []time.Duration
+	for _, review := range reviews {
+		latencies = append(latencies, review.Latency)
+	}
+	stat.MedianTimeToFirstReview = median(latencies)
+	return stat
+}
+
+// reviewsFilter returns the reviews keep returns true for.
+func (s Stats) reviewsFilter(keep func(review Review) bool) map[string][]Review {
+	if s.reviews == nil {
+		return nil
+	}
+	result := make(map[string][]Review)
+	for login, reviews := range s.reviews {
+		for _, review := range reviews {
+			if keep(review) {
+				result[login] = append(result[login], review)
+			}
+		}
+	}
+	return result
+}
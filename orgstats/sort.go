@@ -20,15 +20,86 @@ var ExtractDeletions = func(st Stat) int {
 	return st.Deletions
 }
 
+// ExtractChanges extract the sum of adds and rms of the given stat
+var ExtractChanges = func(st Stat) int {
+	return st.Additions + st.Deletions
+}
+
 // Reviews extract the reviewed prs section of the given stat
 var Reviews = func(st Stat) int {
 	return st.Reviews
 }
 
+// ExtractPullRequests extract the opened prs section of the given stat
+var ExtractPullRequests = func(st Stat) int {
+	return st.PullRequests
+}
+
+// ExtractMergedPullRequests extract the merged prs section of the given stat
+var ExtractMergedPullRequests = func(st Stat) int {
+	return st.MergedPullRequests
+}
+
+// ExtractMedianPullRequestSize extract the median pr size section of the
+// given stat
+var ExtractMedianPullRequestSize = func(st Stat) int {
+	return st.MedianPullRequestSize
+}
+
+// ExtractMedianTimeToMerge extract the median time to merge, in minutes, of
+// the given stat
+var ExtractMedianTimeToMerge = func(st Stat) int {
+	return int(st.MedianTimeToMerge.Minutes())
+}
+
+// ExtractApprovals extract the approvals section of the given stat
+var ExtractApprovals = func(st Stat) int {
+	return st.Approvals
+}
+
+// ExtractChangesRequested extract the change requests section of the given
+// stat
+var ExtractChangesRequested = func(st Stat) int {
+	return st.ChangesRequested
+}
+
+// ExtractReviewComments extract the review comments section of the given
+// stat
+var ExtractReviewComments = func(st Stat) int {
+	return st.ReviewComments
+}
+
+// ExtractMedianTimeToFirstReview extract the median time to first review,
+// in minutes, of the given stat
+var ExtractMedianTimeToFirstReview = func(st Stat) int {
+	return int(st.MedianTimeToFirstReview.Minutes())
+}
+
+// ExtractIssuesOpened extract the opened issues section of the given stat
+var ExtractIssuesOpened = func(st Stat) int {
+	return st.IssuesOpened
+}
+
+// ExtractIssuesClosed extract the closed issues section of the given stat
+var ExtractIssuesClosed = func(st Stat) int {
+	return st.IssuesClosed
+}
+
+// ExtractIssueComments extract the issue comments section of the given stat
+var ExtractIssueComments = func(st Stat) int {
+	return st.IssueComments
+}
+
+// ExtractTriage extract the sum of closed issues and issue comments of the
+// given stat
+var ExtractTriage = func(st Stat) int {
+	return st.IssuesClosed + st.IssueComments
+}
+
 func Sort(s Stats, extract Extract) []StatPair {
 	var result []StatPair
-	for key, value := range s.data {
-		result = append(result, StatPair{Key: key, Value: extract(value)})
+	for key := range s.data {
+		result = append(result, StatPair{Key: key, Value: extract(s.For(key))})
 	}
 	sort.Slice(result, func(i int, j int) bool {
 		return result[i].Value > result[j].Value
@@ -1,26 +1,81 @@
 package orgstats
 
 import (
-	"context"
-	"fmt"
-	"log"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/caarlos0/org-stats/github_errors"
-
 	"github.com/google/go-github/v39/github"
 )
 
 // Stat represents an user adds, rms and commits count
 type Stat struct {
 	Additions, Deletions, Commits, Reviews int
+
+	// PullRequests opened, of which MergedPullRequests were merged and
+	// ClosedPullRequests were closed without being merged.
+	PullRequests, MergedPullRequests, ClosedPullRequests int
+	// MedianTimeToMerge and MedianPullRequestSize, in lines changed, of the
+	// pull requests opened. Medians do not add up, so they are only set by
+	// Stats.For.
+	MedianTimeToMerge     time.Duration
+	MedianPullRequestSize int
+
+	// Approvals, ChangesRequested and ReviewComments given on pull
+	// requests of others.
+	Approvals, ChangesRequested, ReviewComments int
+	// MedianTimeToFirstReview is the median time between a pull request
+	// being opened and the user first reviewing it. It is only set by
+	// Stats.For.
+	MedianTimeToFirstReview time.Duration
+
+	// IssuesOpened, IssuesClosed and IssueComments, not counting pull
+	// requests.
+	IssuesOpened, IssuesClosed, IssueComments int
+}
+
+// plus returns the sum of both stats.
+func (s Stat) plus(o Stat) Stat {
+	return Stat{
+		Additions:          s.Additions + o.Additions,
+		Deletions:          s.Deletions + o.Deletions,
+		Commits:            s.Commits + o.Commits,
+		Reviews:            s.Reviews + o.Reviews,
+		PullRequests:       s.PullRequests + o.PullRequests,
+		MergedPullRequests: s.MergedPullRequests + o.MergedPullRequests,
+		ClosedPullRequests: s.ClosedPullRequests + o.ClosedPullRequests,
+		Approvals:          s.Approvals + o.Approvals,
+		ChangesRequested:   s.ChangesRequested + o.ChangesRequested,
+		ReviewComments:     s.ReviewComments + o.ReviewComments,
+		IssuesOpened:       s.IssuesOpened + o.IssuesOpened,
+		IssuesClosed:       s.IssuesClosed + o.IssuesClosed,
+		IssueComments:      s.IssueComments + o.IssueComments,
+	}
 }
 
 // Stats contains the user->Stat mapping
 type Stats struct {
-	data  map[string]Stat
-	since time.Time
+	data        map[string]Stat
+	orgs        map[string]map[string]Stat
+	bots        map[string]bool
+	since       time.Time
+	until       time.Time
+	unavailable []string
+	// repos is repo->login->Stat, without reviews.
+	repos map[string]map[string]Stat
+	// pulls is login->pull requests, to compute medians.
+	pulls map[string][]PullRequest
+	// reviews is login->first reviews, to compute medians.
+	reviews map[string][]Review
+	// weeks is login->repo->week start->Stat, only kept if weekly.
+	weeks map[string]map[string]map[time.Time]Stat
+	// teams is org/slug->members, only kept if teams were gathered.
+	teams map[string][]string
+
+	botPatterns []Pattern
+	excludeBots bool
+	weekly      bool
+	identities  *Identities
 }
 
 func (s Stats) Logins() []string {
@@ -32,253 +87,174 @@ func (s Stats) Logins() []string {
 }
 
 func (s Stats) For(login string) Stat {
-	return s.data[login]
+	stat := s.data[login]
+	if pulls := s.pulls[login]; len(pulls) > 0 {
+		stat = withMedians(stat, pulls)
+	}
+	if reviews := s.reviews[login]; len(reviews) > 0 {
+		stat = withReviewMedians(stat, reviews)
+	}
+	return stat
 }
 
-// NewStats return a new Stats map
-func NewStats(since time.Time) Stats {
-	return Stats{
-		data:  make(map[string]Stat),
-		since: since,
+// Orgs returns the owners, organizations or users, of the repositories the
+// stats were gathered from, sorted.
+func (s Stats) Orgs() []string {
+	orgs := make([]string, 0, len(s.orgs))
+	for org := range s.orgs {
+		orgs = append(orgs, org)
 	}
+	sort.Strings(orgs)
+	return orgs
 }
 
-// Gather a given organization's stats
-func Gather(
-	ctx context.Context,
-	client *github.Client,
-	org string,
-	userBlacklist, repoBlacklist []string,
-	since time.Time,
-	includeReviewStats bool,
-	excludeForks bool,
-) (Stats, error) {
-
-	allStats := NewStats(since)
-	if err := gatherLineStats(
-		ctx,
-		client,
-		org,
-		userBlacklist,
-		repoBlacklist,
-		excludeForks,
-		&allStats,
-	); err != nil {
-		return Stats{}, err
+// ForOrg returns the stats of the given owner's repositories only.
+func (s Stats) ForOrg(org string) Stats {
+	data := s.orgs[org]
+	if data == nil {
+		data = map[string]Stat{}
 	}
-
-	log.Println("total authors stats:", len(allStats.data))
-
-	if !includeReviewStats {
-		return allStats, nil
+	return Stats{
+		data: data,
+		repos: s.reposFilter(func(repo, _ string) bool {
+			return ownedBy(repo, org)
+		}),
+		weeks: s.weeksFilter(func(_, repo string) bool {
+			return ownedBy(repo, org)
+		}),
+		pulls: s.pullsFilter(func(pr PullRequest) bool {
+			return pr.Org == org
+		}),
+		reviews: s.reviewsFilter(func(review Review) bool {
+			return review.Org == org
+		}),
+		bots:  s.bots,
+		since: s.since,
+		until: s.until,
 	}
+}
 
-	for user := range allStats.data {
-		log.Println("gathering review stats for user:", user)
-		if err := gatherReviewStats(
-			ctx,
-			client,
-			org,
-			user,
-			userBlacklist,
-			repoBlacklist,
-			&allStats,
-			since,
-		); err != nil {
-			return Stats{}, err
-		}
-	}
+// Since returns the start of the period the stats cover, zero if unbounded.
+func (s Stats) Since() time.Time {
+	return s.since
+}
 
-	return allStats, nil
+// Until returns the end, exclusive, of the period the stats cover, zero if
+// unbounded.
+func (s Stats) Until() time.Time {
+	return s.until
 }
 
-func gatherReviewStats(
-	ctx context.Context,
-	client *github.Client,
-	org, user string,
-	userBlacklist, repoBlacklist []string,
-	allStats *Stats,
-	since time.Time,
-) error {
-	ts := since.Format("2006-01-02")
-	// review:approved, review:changes_requested
-	reviewed, err := search(ctx, client, fmt.Sprintf("user:%s is:pr reviewed-by:%s created:>%s", org, user, ts))
-	if err != nil {
-		log.Println("failed to gather review stats for user: ", user, "error: ", err)
-		return err
+// Period describes the period the stats cover, with inclusive dates, as in
+// '2026-07-01 to 2026-09-30'.
+func (s Stats) Period() string {
+	const layout = "2006-01-02"
+	switch {
+	case s.since.IsZero() && s.until.IsZero():
+		return "all time"
+	case s.until.IsZero():
+		return "since " + s.since.Format(layout)
+	case s.since.IsZero():
+		return "until " + s.until.Add(-time.Nanosecond).Format(layout)
+	default:
+		return s.since.Format(layout) + " to " + s.until.Add(-time.Nanosecond).Format(layout)
 	}
-	allStats.addReviewStats(user, reviewed)
-	return nil
 }
 
-func search(
-	ctx context.Context,
-	client *github.Client,
-	query string,
-) (int, error) {
-	log.Printf("searching '%s'", query)
-	result, resp, err := client.Search.Issues(ctx, query, &github.SearchOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 1,
-		},
-	})
-	if rateErr, ok := err.(*github.RateLimitError); ok {
-		handleRateLimit(rateErr)
-		return search(ctx, client, query)
-	}
-	if isSecondRateErr, secondRateErr := githuberrors.IsSecondaryRateLimitError(resp); isSecondRateErr {
-		handleSecondaryRateLimit(secondRateErr)
-		return search(ctx, client, query)
-	}
-	if _, ok := err.(*github.AcceptedError); ok {
-		return search(ctx, client, query)
-	}
-	if err != nil {
-		return 0, fmt.Errorf("failed to search: %s: %w", query, err)
-	}
-	return *result.Total, nil
+// Unavailable returns the repositories GitHub could not compute stats for
+// in time, and are thus missing from the results.
+func (s Stats) Unavailable() []string {
+	return s.unavailable
 }
 
-func gatherLineStats(
-	ctx context.Context,
-	client *github.Client,
-	org string,
-	userBlacklist, repoBlacklist []string,
-	excludeForks bool,
-	allStats *Stats,
-) error {
-	allRepos, err := repos(ctx, client, org)
-	if err != nil {
-		return err
-	}
+// NewStats return a new Stats map
+func NewStats(since time.Time) Stats {
+	return NewStatsBetween(since, time.Time{})
+}
 
-	for _, repo := range allRepos {
-		if excludeForks && *repo.Fork {
-			log.Println("ignoring forked repo:", repo.GetName())
-			continue
-		}
-		if isBlacklisted(repoBlacklist, repo.GetName()) {
-			log.Println("ignoring blacklisted repo:", repo.GetName())
-			continue
-		}
-		stats, serr := getStats(ctx, client, org, *repo.Name)
-		if serr != nil {
-			return serr
-		}
-		for _, cs := range stats {
-			if isBlacklisted(userBlacklist, cs.Author.GetLogin()) {
-				log.Println("ignoring blacklisted author:", cs.Author.GetLogin())
-				continue
-			}
-			log.Println("recording stats for author", cs.Author.GetLogin(), "on repo", repo.GetName())
-			allStats.add(cs)
-		}
+// NewStatsBetween return a new Stats map covering the given period
+func NewStatsBetween(since, until time.Time) Stats {
+	return Stats{
+		data:  make(map[string]Stat),
+		orgs:  make(map[string]map[string]Stat),
+		bots:  make(map[string]bool),
+		since: since,
+		until: until,
 	}
-	return err
 }
 
-func isBlacklisted(blacklist []string, s string) bool {
-	for _, b := range blacklist {
-		if strings.EqualFold(s, b) {
-			return true
-		}
-	}
-	return false
+// ownedBy reports whether the repository, in the owner/name form, belongs
+// to the given owner.
+func ownedBy(repo, owner string) bool {
+	return strings.HasPrefix(strings.ToLower(repo), strings.ToLower(owner)+"/")
 }
 
-func (s *Stats) addReviewStats(user string, reviewed int) {
-	stat := s.data[user]
-	stat.Reviews += reviewed
-	s.data[user] = stat
+// bounded reports whether the stats cover a limited period.
+func (s Stats) bounded() bool {
+	return !s.since.IsZero() || !s.until.IsZero()
 }
 
-func (s *Stats) add(cs *github.ContributorStats) {
-	if cs.GetAuthor() == nil {
-		return
+// covers reports whether the given time is within the period the stats
+// cover.
+func (s Stats) covers(t time.Time) bool {
+	if !s.since.IsZero() && t.Before(s.since) {
+		return false
 	}
-	stat := s.data[cs.GetAuthor().GetLogin()]
-	var adds int
-	var rms int
-	var commits int
-	for _, week := range cs.Weeks {
-		if !s.since.IsZero() && week.Week.Time.UTC().Before(s.since) {
-			continue
-		}
-		adds += *week.Additions
-		rms += *week.Deletions
-		commits += *week.Commits
+	if !s.until.IsZero() && !t.Before(s.until) {
+		return false
+	}
+	return true
+}
+
+// record adds the given stat to the user, both in the org and overall.
+func (s *Stats) record(org, login string, stat Stat) {
+	s.data[login] = s.data[login].plus(stat)
+	if s.orgs[org] == nil {
+		s.orgs[org] = make(map[string]Stat)
 	}
-	stat.Additions += adds
-	stat.Deletions += rms
-	stat.Commits += commits
-	if stat.Additions+stat.Deletions+stat.Commits == 0 && !s.since.IsZero() {
-		// ignore users with no activity when running with a since time
+	s.orgs[org][login] = s.orgs[org][login].plus(stat)
+}
+
+func (s *Stats) addReviewStats(org, user string, reviewed int) {
+	if _, ok := s.orgs[org][user]; !ok && reviewed == 0 {
+		// members that neither committed nor reviewed are left out.
 		return
 	}
-	s.data[cs.GetAuthor().GetLogin()] = stat
+	s.record(org, user, Stat{Reviews: reviewed})
 }
 
-func repos(ctx context.Context, client *github.Client, org string) ([]*github.Repository, error) {
-	opt := &github.RepositoryListByOrgOptions{
-		ListOptions: github.ListOptions{PerPage: 10},
+func (s *Stats) add(org, repo string, cs *github.ContributorStats) {
+	// commits GitHub could not link to an account are still counted, as
+	// unattributed.
+	login := Unattributed
+	if cs.GetAuthor() != nil {
+		login = s.identities.resolve(cs.GetAuthor().GetLogin())
 	}
-	var allRepos []*github.Repository
-	for {
-		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opt)
-		if rateErr, ok := err.(*github.RateLimitError); ok {
-			handleRateLimit(rateErr)
-			continue
-		}
-		if isSecondRateErr, secondRateErr := githuberrors.IsSecondaryRateLimitError(resp); isSecondRateErr {
-			handleSecondaryRateLimit(secondRateErr)
-			continue
-		}
-		if err != nil {
-			return allRepos, err
+	if cs.GetAuthor() != nil && isBot(cs.GetAuthor(), s.botPatterns) {
+		if s.excludeBots {
+			return
 		}
-		allRepos = append(allRepos, repos...)
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.ListOptions.Page = resp.NextPage
+		s.bots[login] = true
 	}
-
-	log.Println("got", len(allRepos), "repositories")
-	return allRepos, nil
-}
-
-func getStats(ctx context.Context, client *github.Client, org, repo string) ([]*github.ContributorStats, error) {
-	stats, resp, err := client.Repositories.ListContributorsStats(ctx, org, repo)
-	if err != nil {
-		if rateErr, ok := err.(*github.RateLimitError); ok {
-			handleRateLimit(rateErr)
-			return getStats(ctx, client, org, repo)
+	var stat Stat
+	for _, week := range cs.Weeks {
+		if !s.covers(week.Week.Time.UTC()) {
+			continue
 		}
-		if isSecondRateErr, secondRateErr := githuberrors.IsSecondaryRateLimitError(resp); isSecondRateErr {
-			handleSecondaryRateLimit(secondRateErr)
-			return getStats(ctx, client, org, repo)
+		weekStat := Stat{
+			Additions: week.GetAdditions(),
+			Deletions: week.GetDeletions(),
+			Commits:   week.GetCommits(),
 		}
-		if _, ok := err.(*github.AcceptedError); ok {
-			return getStats(ctx, client, org, repo)
+		if s.weekly && weekStat != (Stat{}) {
+			s.recordWeek(login, repo, week.Week.Time, weekStat)
 		}
+		stat = stat.plus(weekStat)
 	}
-	return stats, err
-}
-
-func handleRateLimit(err *github.RateLimitError) {
-	s := err.Rate.Reset.UTC().Sub(time.Now().UTC())
-	if s < 0 {
-		s = 5 * time.Second
-	}
-	log.Printf("hit rate limit, waiting %v", s)
-	time.Sleep(s)
-}
-
-func handleSecondaryRateLimit(err *githuberrors.SecondaryRateLimitError) {
-	s := err.RetryAfter.UTC().Sub(time.Now().UTC())
-	if s < 0 {
-		s = 10 * time.Second
+	if stat.Additions+stat.Deletions+stat.Commits == 0 && s.bounded() {
+		// ignore users with no activity when running within a period
+		return
 	}
-	log.Printf("hit secondary rate limit, waiting %v", s)
-	time.Sleep(s)
+	s.record(org, login, stat)
+	s.recordRepo(repo, login, stat)
 }
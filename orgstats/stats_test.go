@@ -0,0 +1,457 @@
+package orgstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/org-stats/github_errors"
+	"github.com/google/go-github/v39/github"
+	"github.com/matryer/is"
+)
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *github.Client {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client := github.NewClient(&http.Client{
+		Transport: githuberrors.NewTransport(http.DefaultTransport),
+	})
+	u, _ := url.Parse(srv.URL + "/")
+	client.BaseURL = u
+	return client
+}
+
+func TestGatherConcurrently(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d","fork":true},{"name":"e"},{"name":"f"},{"name":"g"}]`)
+	})
+	for _, repo := range []string{"a", "b", "c", "e", "f", "g"} {
+		mux.HandleFunc("/repos/foo/"+repo+"/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			// long enough for the other workers to be in flight too.
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+		})
+	}
+
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithExcludeForks(true),
+		WithConcurrency(3),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.Logins(), []string{"alice"})
+	is.Equal(stats.For("alice"), Stat{Additions: 60, Deletions: 12, Commits: 6})
+	is.True(peak.Load() > 1)  // repositories were scanned at the same time
+	is.True(peak.Load() <= 3) // but no more than the concurrency
+}
+
+func TestGatherCanceledWhileRateLimited(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := Gather(ctx, newTestClient(t, mux), "foo")
+
+	is := is.New(t)
+	is.True(errors.Is(err, context.Canceled))
+	is.True(time.Since(start) < time.Second)
+}
+
+func TestGatherStatsUnavailable(t *testing.T) {
+	var attempts int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"b"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+	})
+	mux.HandleFunc("/repos/foo/b/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    4,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		}),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(attempts, 3)
+	is.Equal(stats.Logins(), []string{"alice"})
+	is.Equal(stats.Unavailable(), []string{"foo/b"})
+}
+
+func TestGatherResume(t *testing.T) {
+	var calls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"b"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+	})
+	mux.HandleFunc("/repos/foo/b/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			// first run dies while on this repo.
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `[{"author":{"login":"bob"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}]`)
+	})
+
+	is := is.New(t)
+	client := newTestClient(t, mux)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	_, err := Gather(context.Background(), client, "foo", WithCheckpoint(path))
+	is.True(err != nil)
+	is.Equal(calls, 2)
+
+	_, err = Gather(context.Background(), client, "foo", WithCheckpoint(path), WithPullRequests(true), WithResume(true))
+	is.True(err != nil) // gathered with other options
+	_, err = Gather(context.Background(), client, "foo", WithCheckpoint(path), WithUserBlacklist("bob"), WithResume(true))
+	is.True(err != nil) // gathered with other options
+	is.Equal(calls, 2)
+
+	stats, err := Gather(context.Background(), client, "foo", WithCheckpoint(path), WithResume(true))
+	is.NoErr(err)
+	is.Equal(calls, 3) // only b was scanned again
+	is.Equal(stats.For("alice"), Stat{Additions: 10, Deletions: 2, Commits: 1})
+	is.Equal(stats.For("bob"), Stat{Additions: 1, Deletions: 1, Commits: 1})
+
+	_, err = os.Stat(path)
+	is.True(os.IsNotExist(err)) // removed once done
+}
+
+func TestGatherResumeRelativePeriod(t *testing.T) {
+	var calls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"b"}]`)
+	})
+	for _, repo := range []string{"a", "b"} {
+		mux.HandleFunc("/repos/foo/"+repo+"/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 2 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+		})
+	}
+
+	is := is.New(t)
+	client := newTestClient(t, mux)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	since := time.Now().UTC().Add(-30 * 24 * time.Hour)
+
+	_, err := Gather(context.Background(), client, "foo", WithCheckpoint(path), WithSince(since), WithPeriod("since=30d"))
+	is.True(err != nil)
+
+	// a relative period covers other times on the next run.
+	later := since.Add(time.Hour)
+	_, err = Gather(context.Background(), client, "foo", WithCheckpoint(path), WithSince(later), WithResume(true))
+	is.True(err != nil) // not the same times, nor period
+	_, err = Gather(context.Background(), client, "foo", WithCheckpoint(path), WithSince(later), WithPeriod("since=7d"), WithResume(true))
+	is.True(err != nil) // not the same period
+
+	collector := NewCollector(client, "foo", WithCheckpoint(path), WithSince(later), WithPeriod("since=30d"), WithResume(true))
+	stats, err := collector.Gather(context.Background())
+	is.NoErr(err)
+	is.Equal(calls, 3) // only b was scanned again
+	is.True(stats.Since().Equal(since))
+	is.True(collector.Options().Since.Equal(later)) // the collector is left as is
+}
+
+func TestGatherMultipleOrgs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"skip"}]`)
+	})
+	mux.HandleFunc("/orgs/bar/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"},{"name":"skip"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+	})
+	mux.HandleFunc("/repos/bar/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]},{"author":{"login":"bob"},"weeks":[{"w":1,"a":5,"d":0,"c":2}]}]`)
+	})
+	mux.HandleFunc("/repos/bar/skip/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"carol"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}]`)
+	})
+
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"foo",
+		WithOrgs("bar"),
+		WithRepoBlacklist("foo/skip"),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.Orgs(), []string{"bar", "foo"})
+	is.Equal(stats.For("alice"), Stat{Additions: 11, Deletions: 3, Commits: 2})
+	is.Equal(stats.ForOrg("foo").For("alice"), Stat{Additions: 10, Deletions: 2, Commits: 1})
+	is.Equal(stats.ForOrg("foo").For("bob"), Stat{})
+	is.Equal(stats.ForOrg("bar").For("bob"), Stat{Additions: 5, Commits: 2})
+	is.Equal(stats.ForOrg("bar").For("carol"), Stat{Additions: 1, Deletions: 1, Commits: 1})
+
+	c := NewCollector(nil, "foo", WithOrgs("bar", "foo"))
+	is.Equal(c.Orgs(), []string{"foo", "bar"})
+	is.Equal(c.Org(), "foo")
+	is.Equal(NewCollector(nil, "", WithUsers("alice")).Org(), "")
+}
+
+func TestGatherUsersAndRepos(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/alice/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"dotfiles"}]`)
+	})
+	mux.HandleFunc("/repos/bar/a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"a"}`)
+	})
+	mux.HandleFunc("/repos/alice/dotfiles", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"dotfiles"}`)
+	})
+	mux.HandleFunc("/repos/alice/dotfiles/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]}]`)
+	})
+	mux.HandleFunc("/repos/bar/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}]`)
+	})
+	var queries []string
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"total_count":1}`)
+	})
+
+	stats, err := Gather(
+		context.Background(),
+		newTestClient(t, mux),
+		"",
+		WithUsers("alice"),
+		WithRepos("bar/a", "alice/dotfiles"), // dotfiles is only counted once
+		WithReviews(true),
+	)
+
+	is := is.New(t)
+	is.NoErr(err)
+	is.Equal(stats.Orgs(), []string{"alice", "bar"})
+	is.Equal(stats.For("alice"), Stat{Additions: 11, Deletions: 3, Commits: 2, Reviews: 2})
+	sort.Strings(queries)
+	// reviews are only searched on the repositories given of other owners.
+	is.Equal(len(queries), 2)
+	is.True(strings.HasPrefix(queries[0], "repo:bar/a is:pr reviewed-by:alice "))
+	is.True(strings.HasPrefix(queries[1], "user:alice is:pr reviewed-by:alice "))
+
+	_, err = Gather(context.Background(), newTestClient(t, mux), "", WithRepos("nope"))
+	is.True(err != nil)
+}
+
+func TestGatherBots(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	})
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"author":{"login":"alice","type":"User"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]},
+			{"author":{"login":"dependabot[bot]","type":"User"},"weeks":[{"w":1,"a":100,"d":50,"c":10}]},
+			{"author":{"login":"some-app","type":"Bot"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]},
+			{"author":{"login":"ci-builder","type":"User"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}
+		]`)
+	})
+
+	is := is.New(t)
+
+	stats, err := Gather(context.Background(), newTestClient(t, mux), "foo", WithBotPatterns("ci-*"))
+	is.NoErr(err)
+	is.True(!stats.IsBot("alice"))
+	is.True(stats.IsBot("dependabot[bot]"))
+	is.True(stats.IsBot("some-app"))
+	is.True(stats.IsBot("ci-builder"))
+	is.Equal(stats.Humans().Logins(), []string{"alice"})
+	is.Equal(len(stats.Bots().Logins()), 3)
+	is.Equal(stats.ForOrg("foo").Humans().Logins(), []string{"alice"})
+
+	stats, err = Gather(context.Background(), newTestClient(t, mux), "foo", WithExcludeBots(true))
+	is.NoErr(err)
+	logins := stats.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"alice", "ci-builder"})
+}
+
+func TestStatsPeriod(t *testing.T) {
+	week := func(date string, adds int) *github.WeeklyStats {
+		t, _ := time.Parse("2006-01-02", date)
+		return &github.WeeklyStats{
+			Week:      &github.Timestamp{Time: t},
+			Additions: github.Int(adds),
+			Deletions: github.Int(0),
+			Commits:   github.Int(1),
+		}
+	}
+	cs := &github.ContributorStats{
+		Author: &github.Contributor{Login: github.String("alice")},
+		Weeks: []*github.WeeklyStats{
+			week("2026-06-28", 1),
+			week("2026-07-05", 10),
+			week("2026-09-27", 100),
+			week("2026-10-04", 1000),
+		},
+	}
+	since, _ := time.Parse("2006-01-02", "2026-07-01")
+	until, _ := time.Parse("2006-01-02", "2026-10-01")
+
+	is := is.New(t)
+
+	stats := NewStatsBetween(since, until)
+	stats.add("foo", "foo/a", cs)
+	is.Equal(stats.For("alice"), Stat{Additions: 110, Commits: 2})
+	is.Equal(stats.Period(), "2026-07-01 to 2026-09-30")
+
+	stats = NewStatsBetween(time.Time{}, until)
+	stats.add("foo", "foo/a", cs)
+	is.Equal(stats.For("alice"), Stat{Additions: 111, Commits: 3})
+	is.Equal(stats.Period(), "until 2026-09-30")
+
+	is.Equal(NewStats(time.Time{}).Period(), "all time")
+}
+
+func TestStatsWeekly(t *testing.T) {
+	week := func(date string, commits int) *github.WeeklyStats {
+		t, _ := time.Parse("2006-01-02", date)
+		return &github.WeeklyStats{
+			Week:      &github.Timestamp{Time: t},
+			Additions: github.Int(commits * 10),
+			Deletions: github.Int(commits),
+			Commits:   github.Int(commits),
+		}
+	}
+	alice := &github.ContributorStats{
+		Author: &github.Contributor{Login: github.String("alice")},
+		Weeks: []*github.WeeklyStats{
+			week("2026-06-28", 1),
+			week("2026-07-05", 2),
+			week("2026-07-12", 0),
+			week("2026-07-19", 3),
+		},
+	}
+	bob := &github.ContributorStats{
+		Author: &github.Contributor{Login: github.String("bob")},
+		Weeks: []*github.WeeklyStats{
+			week("2026-07-05", 5),
+		},
+	}
+
+	is := is.New(t)
+
+	stats := NewStats(time.Time{})
+	stats.add("foo", "foo/a", alice)
+	is.Equal(len(stats.WeeksFor("alice")), 0) // not weekly
+
+	stats = NewStats(time.Time{})
+	stats.weekly = true
+	stats.add("foo", "foo/a", alice)
+	stats.add("bar", "bar/b", alice)
+	stats.add("foo", "foo/a", bob)
+
+	weeks := stats.WeeksFor("alice")
+	is.Equal(len(weeks), 3)
+	is.Equal(weeks[0].Start.Format("2006-01-02"), "2026-06-28")
+	is.Equal(weeks[1].Stat, Stat{Additions: 40, Deletions: 4, Commits: 4})
+	is.Equal(LongestStreak(weeks), 2)
+
+	weeks = stats.WeeksForRepo("foo/a")
+	is.Equal(len(weeks), 3)
+	is.Equal(weeks[1].Stat, Stat{Additions: 70, Deletions: 7, Commits: 7})
+
+	weeks = stats.ForOrg("bar").WeeksFor("alice")
+	is.Equal(weeks[1].Stat, Stat{Additions: 20, Deletions: 2, Commits: 2})
+	is.Equal(len(stats.ForOrg("bar").WeeksFor("bob")), 0)
+}
+
+func TestStatsByRepo(t *testing.T) {
+	contributor := func(login string, adds int) *github.ContributorStats {
+		t, _ := time.Parse("2006-01-02", "2026-07-05")
+		return &github.ContributorStats{
+			Author: &github.Contributor{Login: github.String(login)},
+			Weeks: []*github.WeeklyStats{{
+				Week:      &github.Timestamp{Time: t},
+				Additions: github.Int(adds),
+				Deletions: github.Int(0),
+				Commits:   github.Int(1),
+			}},
+		}
+	}
+
+	is := is.New(t)
+
+	stats := NewStats(time.Time{})
+	stats.add("foo", "foo/a", contributor("alice", 10))
+	stats.add("foo", "foo/a", contributor("bob", 20))
+	stats.add("foo", "foo/b", contributor("alice", 30))
+	stats.add("bar", "bar/c", contributor("alice", 40))
+	stats.addReviewStats("foo", "alice", 5)
+
+	is.Equal(stats.Repos(), []string{"bar/c", "foo/a", "foo/b"})
+	is.Equal(stats.For("alice"), Stat{Additions: 80, Commits: 3, Reviews: 5})
+	is.Equal(stats.ForRepo("foo/a").For("alice"), Stat{Additions: 10, Commits: 1})
+	is.Equal(stats.ForRepo("foo/a").For("bob"), Stat{Additions: 20, Commits: 1})
+	is.Equal(stats.ReposFor("alice"), map[string]Stat{
+		"foo/a": {Additions: 10, Commits: 1},
+		"foo/b": {Additions: 30, Commits: 1},
+		"bar/c": {Additions: 40, Commits: 1},
+	})
+	is.Equal(stats.ForOrg("foo").Repos(), []string{"foo/a", "foo/b"})
+}
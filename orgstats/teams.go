@@ -0,0 +1,187 @@
+package orgstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// recordTeam keeps the members of the given team, in the org/slug form.
+func (s *Stats) recordTeam(team string, members []string) {
+	if s.teams == nil {
+		s.teams = make(map[string][]string)
+	}
+	s.teams[team] = members
+}
+
+// Teams returns the teams gathered, in the org/slug form, sorted.
+func (s Stats) Teams() []string {
+	teams := make([]string, 0, len(s.teams))
+	for team := range s.teams {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// TeamsFor returns the teams, in the org/slug form, the given user is a
+// member of, sorted.
+func (s Stats) TeamsFor(login string) []string {
+	var teams []string
+	for _, team := range s.Teams() {
+		if containsFold(s.teams[team], login) {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
+
+// ByTeam returns the stats rolled up per team: the logins are the teams,
+// in the org/slug form, with the sum of their members' stats, and medians
+// over all their pull requests and reviews.
+//
+// People on several teams count towards each one of them, so the teams'
+// stats don't add up to the overall ones. Reviews are counted for each
+// member that reviewed, so a pull request reviewed by two members of a team
+// counts twice.
+func (s Stats) ByTeam() Stats {
+	result := NewStatsBetween(s.since, s.until)
+	result.unavailable = s.unavailable
+	for _, team := range s.Teams() {
+		org, _, _ := strings.Cut(team, "/")
+		for _, login := range s.teams[team] {
+			stat, ok := s.data[login]
+			if !ok {
+				continue
+			}
+			result.record(org, team, stat)
+			for repo, repoStat := range s.ReposFor(login) {
+				result.recordRepo(repo, team, repoStat)
+			}
+			for _, pr := range s.pulls[login] {
+				pr.Author = team
+				result.recordPull(pr)
+			}
+			for _, review := range s.reviews[login] {
+				review.Reviewer = team
+				result.recordReview(review)
+			}
+			for repo, weeks := range s.weeks[login] {
+				for start, weekStat := range weeks {
+					result.recordWeek(team, repo, start, weekStat)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// teamRef is a team to gather, and whether its organization was given
+// explicitly, as in org/slug.
+type teamRef struct {
+	org, slug string
+	explicit  bool
+}
+
+// teamRefs returns the teams to gather: the OnlyTeams, with the ones given
+// as a plain slug looked up on every organization, or all the teams of the
+// organizations.
+func (c *Collector) teamRefs(ctx context.Context) ([]teamRef, error) {
+	var refs []teamRef
+	if len(c.opts.OnlyTeams) == 0 {
+		for _, org := range c.orgs {
+			teams, err := c.listTeams(ctx, org)
+			if err != nil {
+				return nil, err
+			}
+			for _, team := range teams {
+				refs = append(refs, teamRef{org: org, slug: team.GetSlug(), explicit: true})
+			}
+		}
+		return refs, nil
+	}
+	for _, team := range c.opts.OnlyTeams {
+		if org, slug, ok := strings.Cut(team, "/"); ok {
+			refs = append(refs, teamRef{org: org, slug: slug, explicit: true})
+			continue
+		}
+		for _, org := range c.orgs {
+			refs = append(refs, teamRef{org: org, slug: team})
+		}
+	}
+	return refs, nil
+}
+
+// gatherTeams records the teams to break the stats down by, and their
+// members. With OnlyTeams, the stats of each organization are then
+// restricted to the members of its teams.
+func (c *Collector) gatherTeams(ctx context.Context, stats *Stats) error {
+	refs, err := c.teamRefs(ctx)
+	if err != nil {
+		return err
+	}
+	c.onlyMembers = nil
+	if len(c.opts.OnlyTeams) > 0 {
+		c.onlyMembers = map[string]map[string]bool{}
+	}
+	for _, ref := range refs {
+		members, err := c.teamMembers(ctx, ref.org, ref.slug)
+		var gerr *github.ErrorResponse
+		if !ref.explicit && errors.As(err, &gerr) && gerr.Response.StatusCode == http.StatusNotFound {
+			log.Println("team", ref.slug, "not found on org", ref.org)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logins := make([]string, 0, len(members))
+		for _, member := range members {
+			login := c.opts.Identities.resolve(member.GetLogin())
+			if !containsFold(logins, login) {
+				logins = append(logins, login)
+			}
+			if c.onlyMembers != nil {
+				org := strings.ToLower(ref.org)
+				if c.onlyMembers[org] == nil {
+					c.onlyMembers[org] = map[string]bool{}
+				}
+				c.onlyMembers[org][strings.ToLower(login)] = true
+			}
+		}
+		sort.Strings(logins)
+		stats.recordTeam(ref.org+"/"+ref.slug, logins)
+	}
+	if len(c.opts.OnlyTeams) > 0 && len(stats.teams) == 0 {
+		return fmt.Errorf("none of the teams %v were found", c.opts.OnlyTeams)
+	}
+	log.Println("got", len(stats.teams), "teams")
+	return nil
+}
+
+// listTeams returns the teams of the organization.
+func (c *Collector) listTeams(ctx context.Context, org string) ([]*github.Team, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var result []*github.Team
+	for {
+		var teams []*github.Team
+		var resp *github.Response
+		if err := c.do(ctx, "teams "+org, func() error {
+			var err error
+			teams, resp, err = c.client.Teams.ListTeams(ctx, org, opts)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list teams: %s: %w", org, err)
+		}
+		result = append(result, teams...)
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
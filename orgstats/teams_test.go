@@ -0,0 +1,83 @@
+package orgstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGatherTeams(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, org := range []string{"foo", "bar"} {
+		mux.HandleFunc("/orgs/"+org+"/repos", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"a"}]`)
+		})
+	}
+	mux.HandleFunc("/repos/foo/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"author":{"login":"alice"},"weeks":[{"w":1,"a":10,"d":2,"c":1}]},
+			{"author":{"login":"bob"},"weeks":[{"w":1,"a":20,"d":4,"c":2}]},
+			{"author":{"login":"carol"},"weeks":[{"w":1,"a":40,"d":8,"c":4}]}
+		]`)
+	})
+	mux.HandleFunc("/repos/bar/a/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"author":{"login":"alice"},"weeks":[{"w":1,"a":1,"d":1,"c":1}]}]`)
+	})
+	mux.HandleFunc("/orgs/foo/teams", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"slug":"backend"},{"slug":"frontend"},{"slug":"empty"}]`)
+	})
+	mux.HandleFunc("/orgs/bar/teams", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/orgs/foo/teams/backend/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"}]`)
+	})
+	mux.HandleFunc("/orgs/foo/teams/frontend/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"login":"alice"}]`)
+	})
+	mux.HandleFunc("/orgs/foo/teams/empty/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/orgs/bar/teams/backend/members", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	})
+
+	is := is.New(t)
+
+	stats, err := Gather(context.Background(), newTestClient(t, mux), "foo", WithOrgs("bar"), WithTeams(true))
+	is.NoErr(err)
+	is.Equal(stats.Teams(), []string{"foo/backend", "foo/empty", "foo/frontend"})
+	is.Equal(stats.TeamsFor("alice"), []string{"foo/backend", "foo/frontend"})
+	is.Equal(len(stats.TeamsFor("carol")), 0)
+	is.Equal(len(stats.Logins()), 3)
+
+	byTeam := stats.ByTeam()
+	logins := byTeam.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"foo/backend", "foo/frontend"})
+	// alice counts towards both of her teams, with her stats on every org.
+	is.Equal(byTeam.For("foo/backend"), Stat{Additions: 31, Deletions: 7, Commits: 4})
+	is.Equal(byTeam.For("foo/frontend"), Stat{Additions: 11, Deletions: 3, Commits: 2})
+	is.Equal(byTeam.Repos(), []string{"bar/a", "foo/a"})
+	is.Equal(byTeam.ForRepo("foo/a").For("foo/backend"), Stat{Additions: 30, Deletions: 6, Commits: 3})
+
+	stats, err = Gather(context.Background(), newTestClient(t, mux), "foo", WithOrgs("bar"), WithOnlyTeams("backend"))
+	is.NoErr(err)
+	is.Equal(stats.Teams(), []string{"foo/backend"})
+	logins = stats.Logins()
+	sort.Strings(logins)
+	is.Equal(logins, []string{"alice", "bob"})
+	// alice is not on any backend team of bar, so her stats there are left
+	// out.
+	is.Equal(stats.For("alice"), Stat{Additions: 10, Deletions: 2, Commits: 1})
+
+	_, err = Gather(context.Background(), newTestClient(t, mux), "bar", WithOnlyTeams("backend"))
+	is.True(err != nil) // no team found
+	_, err = Gather(context.Background(), newTestClient(t, mux), "", WithRepos("foo/a"), WithTeams(true))
+	is.True(err != nil) // no organization
+}
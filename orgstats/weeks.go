@@ -0,0 +1,115 @@
+package orgstats
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Week is the activity within the week starting at Start, a Sunday, as
+// reported by GitHub.
+type Week struct {
+	Start time.Time `json:"start"`
+	Stat
+}
+
+// recordWeek adds the given stat to the user's week in the repository.
+func (s *Stats) recordWeek(login, repo string, start time.Time, stat Stat) {
+	if s.weeks == nil {
+		s.weeks = make(map[string]map[string]map[time.Time]Stat)
+	}
+	if s.weeks[login] == nil {
+		s.weeks[login] = make(map[string]map[time.Time]Stat)
+	}
+	if s.weeks[login][repo] == nil {
+		s.weeks[login][repo] = make(map[time.Time]Stat)
+	}
+	start = start.UTC()
+	s.weeks[login][repo][start] = s.weeks[login][repo][start].plus(stat)
+}
+
+// WeeksFor returns the weekly activity of the given user, oldest first.
+//
+// It is only available if the stats were gathered WithWeekly.
+func (s Stats) WeeksFor(login string) []Week {
+	byStart := map[time.Time]Stat{}
+	for _, weeks := range s.weeks[login] {
+		for start, stat := range weeks {
+			byStart[start] = byStart[start].plus(stat)
+		}
+	}
+	return sortedWeeks(byStart)
+}
+
+// WeeksForRepo returns the weekly activity in the given repository, in the
+// owner/name form, oldest first.
+//
+// It is only available if the stats were gathered WithWeekly.
+func (s Stats) WeeksForRepo(repo string) []Week {
+	byStart := map[time.Time]Stat{}
+	for _, repos := range s.weeks {
+		for name, weeks := range repos {
+			if !strings.EqualFold(name, repo) {
+				continue
+			}
+			for start, stat := range weeks {
+				byStart[start] = byStart[start].plus(stat)
+			}
+		}
+	}
+	return sortedWeeks(byStart)
+}
+
+// LongestStreak returns the most consecutive weeks with commits.
+func LongestStreak(weeks []Week) int {
+	var longest, current int
+	var last time.Time
+	for _, week := range weeks {
+		if week.Commits == 0 {
+			current = 0
+			continue
+		}
+		if current > 0 && week.Start.Sub(last) == 7*24*time.Hour {
+			current++
+		} else {
+			current = 1
+		}
+		last = week.Start
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// weeksFilter returns the weekly activity of the logins and repositories
+// keep returns true for.
+func (s Stats) weeksFilter(keep func(login, repo string) bool) map[string]map[string]map[time.Time]Stat {
+	if s.weeks == nil {
+		return nil
+	}
+	result := make(map[string]map[string]map[time.Time]Stat)
+	for login, repos := range s.weeks {
+		for repo, weeks := range repos {
+			if !keep(login, repo) {
+				continue
+			}
+			if result[login] == nil {
+				result[login] = make(map[string]map[time.Time]Stat)
+			}
+			result[login][repo] = weeks
+		}
+	}
+	return result
+}
+
+func sortedWeeks(byStart map[time.Time]Stat) []Week {
+	weeks := make([]Week, 0, len(byStart))
+	for start, stat := range byStart {
+		weeks = append(weeks, Week{Start: start, Stat: stat})
+	}
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].Start.Before(weeks[j].Start)
+	})
+	return weeks
+}